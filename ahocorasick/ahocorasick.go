@@ -0,0 +1,263 @@
+// Package ahocorasick implements the Aho-Corasick string matching algorithm.
+//
+// An automaton is compiled once from a set of patterns and then finds
+// every occurrence of all of them in a text in a single pass, following
+// failure links instead of restarting at every offset. Non-overlapping
+// leftmost longest matches may rescan up to the length of the longest
+// pattern after each match. Patterns and texts are compared byte by
+// byte, and match offsets are byte offsets.
+package ahocorasick
+
+import (
+	"iter"
+
+	"github.com/cosn/collections/trie"
+)
+
+// Mode selects which occurrences of the patterns are reported.
+type Mode int
+
+const (
+	// Overlapping reports every occurrence of every pattern.
+	Overlapping Mode = iota
+	// LeftmostLongest reports non-overlapping occurrences, scanning from
+	// the left and preferring the longest pattern starting at a position.
+	LeftmostLongest
+)
+
+// Match is an occurrence of a pattern in a text, which spans
+// text[Start:End], along with the pattern's value.
+type Match struct {
+	Pattern    string
+	Start, End int
+	Value      interface{}
+}
+
+// Automaton is the internal representation of an Aho-Corasick automaton.
+type Automaton struct {
+	states []state
+	// root holds every transition of the root, which the scan
+	// returns to on most bytes of a text that rarely matches
+	root     [256]int
+	patterns []string
+	values   []interface{}
+}
+
+// state is a node of the automaton's trie of patterns.
+type state struct {
+	next map[byte]int
+	// fail is the state for the longest proper suffix of this state's
+	// string that is also a prefix of a pattern
+	fail int
+	// out is the pattern ending at this state, or -1
+	out int
+	// dict is the nearest state on the failure chain with a pattern, or -1
+	dict  int
+	depth int
+}
+
+// New returns an automaton matching the given patterns, which have no value.
+// Empty patterns are ignored.
+// O(m) where m is the total length of the patterns
+func New(patterns ...string) *Automaton {
+	return FromSeq(func(yield func(string, interface{}) bool) {
+		for _, p := range patterns {
+			if !yield(p, nil) {
+				return
+			}
+		}
+	})
+}
+
+// FromTrie returns an automaton matching the words of a trie, which keep their values.
+// The empty word is ignored.
+// O(m) where m is the total length of the words
+func FromTrie(t *trie.T) *Automaton {
+	return FromSeq(t.PrefixSeq(""))
+}
+
+// FromSeq returns an automaton matching the patterns of a sequence of
+// patterns and values. If a pattern repeats, its first value is kept.
+// Empty patterns are ignored.
+// O(m) where m is the total length of the patterns
+func FromSeq(seq iter.Seq2[string, interface{}]) *Automaton {
+	a := &Automaton{states: []state{newState(0)}}
+
+	for p, v := range seq {
+		if len(p) > 0 {
+			a.add(p, v)
+		}
+	}
+
+	for b, n := range a.states[0].next {
+		a.root[b] = n
+	}
+
+	a.link()
+	return a
+}
+
+// newState returns a state without transitions or patterns.
+func newState(depth int) state {
+	return state{next: make(map[byte]int), out: -1, dict: -1, depth: depth}
+}
+
+// add inserts a pattern into the trie of the automaton.
+func (a *Automaton) add(p string, v interface{}) {
+	s := 0
+	for i := 0; i < len(p); i++ {
+		n, ok := a.states[s].next[p[i]]
+		if !ok {
+			n = len(a.states)
+			a.states = append(a.states, newState(i+1))
+			a.states[s].next[p[i]] = n
+		}
+		s = n
+	}
+
+	if a.states[s].out == -1 {
+		a.states[s].out = len(a.patterns)
+		a.patterns = append(a.patterns, p)
+		a.values = append(a.values, v)
+	}
+}
+
+// link computes the failure and dictionary links of all the states,
+// breadth first so that the links of shallower states are known.
+func (a *Automaton) link() {
+	queue := []int{0}
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		for b, n := range a.states[s].next {
+			queue = append(queue, n)
+			if s == 0 {
+				// the children of the root can only fail back to it
+				continue
+			}
+
+			f := a.step(a.states[s].fail, b)
+			a.states[n].fail = f
+
+			if a.states[f].out != -1 {
+				a.states[n].dict = f
+			} else {
+				a.states[n].dict = a.states[f].dict
+			}
+		}
+	}
+}
+
+// step returns the state following s on byte b, following
+// failure links until a transition is found.
+func (a *Automaton) step(s int, b byte) int {
+	for s != 0 {
+		if n, ok := a.states[s].next[b]; ok {
+			return n
+		}
+		s = a.states[s].fail
+	}
+
+	return a.root[b]
+}
+
+// Len returns the number of distinct patterns of the automaton.
+// O(1)
+func (a *Automaton) Len() int {
+	return len(a.patterns)
+}
+
+// FindAll returns the occurrences of the patterns in a text.
+// Overlapping: O(n+z) LeftmostLongest: O(n+z*l) where n is the length of
+// the text, z is the number of matches and l is the longest pattern's length
+func (a *Automaton) FindAll(text string, mode Mode) (matches []Match) {
+	for m := range a.Scan(text, mode) {
+		matches = append(matches, m)
+	}
+
+	return
+}
+
+// Scan returns an iterator over the occurrences of the patterns in a text.
+// Overlapping matches are ordered by their end, and then from the longest
+// to the shortest. LeftmostLongest matches are ordered by their start.
+// Matches are found lazily, so stopping early skips the rest of the text.
+// Overlapping: O(n+z) LeftmostLongest: O(n+z*l) where n is the length of
+// the text, z is the number of matches and l is the longest pattern's length
+func (a *Automaton) Scan(text string, mode Mode) iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		if mode == LeftmostLongest {
+			a.leftmostLongest(text, yield)
+		} else {
+			a.overlapping(text, yield)
+		}
+	}
+}
+
+// overlapping yields every occurrence of every pattern.
+func (a *Automaton) overlapping(text string, yield func(Match) bool) {
+	s := 0
+	for i := 0; i < len(text); i++ {
+		s = a.step(s, text[i])
+
+		for o := a.output(s); o != -1; o = a.states[o].dict {
+			if !yield(a.match(o, i+1)) {
+				return
+			}
+		}
+	}
+}
+
+// leftmostLongest yields the non-overlapping occurrences of the patterns.
+// The best candidate seen so far is final once the current state no
+// longer reaches back to its start, since no later match can start there.
+// Scanning then restarts from the root at the end of the candidate, so
+// the bytes read past it, at most the longest pattern's length, are read again.
+func (a *Automaton) leftmostLongest(text string, yield func(Match) bool) {
+	for pos := 0; pos < len(text); {
+		var best Match
+		found := false
+
+		s := 0
+		for i := pos; i < len(text); i++ {
+			s = a.step(s, text[i])
+
+			for o := a.output(s); o != -1; o = a.states[o].dict {
+				m := a.match(o, i+1)
+				if !found || m.Start < best.Start || m.Start == best.Start && m.End > best.End {
+					best, found = m, true
+				}
+			}
+
+			if found && i+1-a.states[s].depth > best.Start {
+				break
+			}
+		}
+
+		if !found || !yield(best) {
+			return
+		}
+
+		pos = best.End
+	}
+}
+
+// output returns the state itself if a pattern ends there, or the
+// first state with a pattern on its dictionary chain, or -1.
+func (a *Automaton) output(s int) int {
+	if a.states[s].out != -1 {
+		return s
+	}
+
+	return a.states[s].dict
+}
+
+// match returns the occurrence of the pattern of state s ending at end.
+func (a *Automaton) match(s, end int) Match {
+	o := a.states[s].out
+	p := a.patterns[o]
+
+	return Match{p, end - len(p), end, a.values[o]}
+}
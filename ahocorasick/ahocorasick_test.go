@@ -0,0 +1,228 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cosn/collections/trie"
+)
+
+func TestOverlapping(t *testing.T) {
+	a := New("he", "she", "his", "hers")
+
+	expected := []Match{
+		{"she", 1, 4, nil},
+		{"he", 2, 4, nil},
+		{"hers", 2, 6, nil},
+	}
+
+	if m := a.FindAll("ushers", Overlapping); !slices.Equal(m, expected) {
+		t.Errorf("Expected %v, but was %v", expected, m)
+	}
+
+	if m := a.FindAll("xyz", Overlapping); len(m) != 0 {
+		t.Errorf("Did not expect any matches: %v", m)
+	}
+}
+
+func TestLeftmostLongest(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		text     string
+		expected []string
+	}{
+		{[]string{"he", "she", "his", "hers"}, "ushers", []string{"she"}},
+		{[]string{"a", "ab", "abc", "bcd"}, "abcd", []string{"abc"}},
+		{[]string{"abcd", "bc"}, "abcbcd", []string{"bc", "bc"}},
+		{[]string{"b", "abcd"}, "abce", []string{"b"}},
+		{[]string{"a", "aa"}, "aaaaa", []string{"aa", "aa", "a"}},
+		{[]string{"foo", "foobar", "bar"}, "foobarbar foo", []string{"foobar", "bar", "foo"}},
+		{[]string{"x"}, "", nil},
+	}
+
+	for _, tt := range tests {
+		var m []string
+		for match := range New(tt.patterns...).Scan(tt.text, LeftmostLongest) {
+			if tt.text[match.Start:match.End] != match.Pattern {
+				t.Errorf("Match %v doesn't span its pattern in %q", match, tt.text)
+			}
+			m = append(m, match.Pattern)
+		}
+
+		if !slices.Equal(m, tt.expected) {
+			t.Errorf("Patterns %q in %q expected %q, but was %q", tt.patterns, tt.text, tt.expected, m)
+		}
+	}
+}
+
+func TestFromTrie(t *testing.T) {
+	tr := new(trie.T)
+	tr.Init(256)
+
+	for _, s := range []string{"error", "err", "warn", "café", "日本"} {
+		tr.Insert(s, strings.ToUpper(s))
+	}
+
+	a := FromTrie(tr)
+	if l := a.Len(); l != tr.Len() {
+		t.Errorf("Automaton expected to have %v patterns, but has %v", tr.Len(), l)
+	}
+
+	text := "warn: café error in 日本"
+	expected := []Match{
+		{"warn", 0, 4, "WARN"},
+		{"café", 6, 11, "CAFÉ"},
+		{"err", 12, 15, "ERR"},
+		{"error", 12, 17, "ERROR"},
+		{"日本", 21, 27, "日本"},
+	}
+
+	if m := a.FindAll(text, Overlapping); !slices.Equal(m, expected) {
+		t.Errorf("Expected %v, but was %v", expected, m)
+	}
+}
+
+func TestNew_Duplicates(t *testing.T) {
+	a := New("ab", "", "ab", "b")
+
+	if l := a.Len(); l != 2 {
+		t.Errorf("Automaton expected to have %v patterns, but has %v", 2, l)
+	}
+
+	if m := a.FindAll("ab", Overlapping); len(m) != 2 {
+		t.Errorf("Expected %v matches, but found %v", 2, m)
+	}
+}
+
+func TestScan_EarlyBreak(t *testing.T) {
+	a := New("a")
+
+	for _, mode := range []Mode{Overlapping, LeftmostLongest} {
+		n := 0
+		for range a.Scan("aaaaa", mode) {
+			if n++; n == 2 {
+				break
+			}
+		}
+
+		if n != 2 {
+			t.Errorf("Mode %v: expected to stop after %v matches, but found %v", mode, 2, n)
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		patterns := make([]string, 1+rand.Intn(8))
+		for j := range patterns {
+			patterns[j] = random(1 + rand.Intn(4))
+		}
+		text := random(rand.Intn(40))
+		a := New(patterns...)
+
+		overlapping := naive(patterns, text)
+		if m := a.FindAll(text, Overlapping); !slices.Equal(m, overlapping) {
+			t.Fatalf("Patterns %q in %q expected %v, but was %v", patterns, text, overlapping, m)
+		}
+
+		// keep the leftmost longest occurrences that don't overlap
+		slices.SortFunc(overlapping, func(x, y Match) int {
+			if x.Start != y.Start {
+				return x.Start - y.Start
+			}
+			return y.End - x.End
+		})
+
+		var expected []Match
+		for _, m := range overlapping {
+			if len(expected) == 0 || m.Start >= expected[len(expected)-1].End {
+				expected = append(expected, m)
+			}
+		}
+
+		if m := a.FindAll(text, LeftmostLongest); !slices.Equal(m, expected) {
+			t.Fatalf("Patterns %q in %q expected %v, but was %v", patterns, text, expected, m)
+		}
+	}
+}
+
+// random returns a random string of length n over a small alphabet.
+func random(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = "abc"[rand.Intn(3)]
+	}
+
+	return string(b)
+}
+
+// naive returns the occurrences of the patterns in a text by checking
+// every pattern at every offset, in the order of Overlapping.
+func naive(patterns []string, text string) (matches []Match) {
+	for end := 1; end <= len(text); end++ {
+		var found []string
+		for _, p := range patterns {
+			if strings.HasSuffix(text[:end], p) && !slices.Contains(found, p) {
+				found = append(found, p)
+			}
+		}
+
+		slices.SortFunc(found, func(x, y string) int { return len(y) - len(x) })
+		for _, p := range found {
+			matches = append(matches, Match{p, end - len(p), end, nil})
+		}
+	}
+
+	return
+}
+
+// keywords returns n distinct keywords and a text of length l containing some of them.
+func keywords(n, l int) ([]string, string) {
+	r := rand.New(rand.NewSource(1))
+	k := make([]string, n)
+	for i := range k {
+		k[i] = fmt.Sprintf("kw%dx%d", r.Intn(1000), i)
+	}
+
+	var b strings.Builder
+	for b.Len() < l {
+		if r.Intn(10) == 0 {
+			b.WriteString(k[r.Intn(n)])
+		} else {
+			b.WriteString("some log line text ")
+		}
+	}
+
+	return k, b.String()
+}
+
+func BenchmarkScan(b *testing.B) {
+	k, text := keywords(5000, 1<<12)
+	a := New(k...)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range a.Scan(text, Overlapping) {
+		}
+	}
+}
+
+func BenchmarkScanTrie(b *testing.B) {
+	k, text := keywords(5000, 1<<12)
+	tr := new(trie.T)
+	tr.Init(256)
+	for _, s := range k {
+		tr.Insert(s, nil)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range text {
+			for range tr.AllPrefixesOf(text[j:]) {
+			}
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Package bloom implements a bloom filter.
+//
+// The filter uses a deterministic hash, so filters built by different
+// processes can be combined and shipped between them.
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+)
+
+var (
+	// ErrIncompatible is returned when combining filters of different shapes.
+	ErrIncompatible = errors.New("bloom: filters have a different number of bits or hashes")
+	// ErrInvalidData is returned when unmarshaling data that isn't a filter.
+	ErrInvalidData = errors.New("bloom: invalid filter data")
+)
+
+// version is the first byte of the binary encoding of a filter.
+const version = 1
+
+// maxHashes is the largest number of hash functions of a filter. Even a
+// false positive rate of 1e-300 needs fewer, and it bounds the work of
+// each operation on a filter read from untrusted data.
+const maxHashes = 1024
+
+// Filter is the internal representation of a bloom filter.
+type Filter struct {
+	bits []uint64
+	m    uint64
+	k    uint64
+}
+
+// New returns a filter sized to hold n items with a false positive
+// rate of at most p, which must be between 0 and 1.
+// O(m)
+func New(n int, p float64) *Filter {
+	if p <= 0 || p >= 1 {
+		panic("Bloom filter false positive rate must be between 0 and 1")
+	}
+
+	if n < 1 {
+		n = 1
+	}
+
+	// optimal number of bits and hash functions for n and p
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+
+	return NewWithSize(int(m), int(min(max(k, 1), maxHashes)))
+}
+
+// NewWithSize returns a filter with m bits and k hash functions,
+// where k is at most 1024.
+// O(m)
+func NewWithSize(m, k int) *Filter {
+	if m < 1 || k < 1 {
+		panic("Bloom filter size and number of hashes must be positive numbers")
+	}
+
+	if k > maxHashes {
+		panic("Bloom filter number of hashes must be at most 1024")
+	}
+
+	return &Filter{
+		bits: make([]uint64, (m+63)/64),
+		m:    uint64(m),
+		k:    uint64(k),
+	}
+}
+
+// Add adds an item to the filter.
+// O(k)
+func (f *Filter) Add(b []byte) {
+	add(f, b)
+}
+
+// AddString adds an item to the filter.
+// O(k)
+func (f *Filter) AddString(s string) {
+	add(f, s)
+}
+
+// add sets the k bits of an item.
+func add[S []byte | string](f *Filter, s S) {
+	h1, h2 := hash(s)
+	for i := uint64(0); i < f.k; i++ {
+		b := (h1 + i*h2) % f.m
+		f.bits[b/64] |= 1 << (b % 64)
+	}
+}
+
+// MayContain returns false if the item was definitely never added to
+// the filter, and true if it probably was.
+// O(k)
+func (f *Filter) MayContain(b []byte) bool {
+	return contains(f, b)
+}
+
+// MayContainString returns false if the item was definitely never added
+// to the filter, and true if it probably was.
+// O(k)
+func (f *Filter) MayContainString(s string) bool {
+	return contains(f, s)
+}
+
+// contains returns true if all the k bits of an item are set.
+func contains[S []byte | string](f *Filter, s S) bool {
+	h1, h2 := hash(s)
+	for i := uint64(0); i < f.k; i++ {
+		b := (h1 + i*h2) % f.m
+		if f.bits[b/64]&(1<<(b%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hash returns the two hashes of an item from which the k bit positions
+// are derived, as in Kirsch and Mitzenmacher's double hashing.
+// The first is a 64 bit FNV-1a hash, the second is derived from it with
+// the splitmix64 finalizer and forced to be odd so it never repeats early.
+func hash[S []byte | string](s S) (uint64, uint64) {
+	const (
+		offset = 14695981039346656037
+		prime  = 1099511628211
+	)
+
+	h := uint64(offset)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= prime
+	}
+
+	g := h
+	g = (g ^ (g >> 30)) * 0xbf58476d1ce4e5b9
+	g = (g ^ (g >> 27)) * 0x94d049bb133111eb
+	g ^= g >> 31
+
+	return h, g | 1
+}
+
+// Union adds all the items of o to the filter.
+// Both filters must have the same number of bits and hashes.
+// O(m)
+func (f *Filter) Union(o *Filter) error {
+	if !f.compatible(o) {
+		return ErrIncompatible
+	}
+
+	for i := range f.bits {
+		f.bits[i] |= o.bits[i]
+	}
+
+	return nil
+}
+
+// Intersect keeps only the bits of the filter that are also set in o,
+// which approximates the items added to both filters. Both filters
+// must have the same number of bits and hashes.
+// O(m)
+func (f *Filter) Intersect(o *Filter) error {
+	if !f.compatible(o) {
+		return ErrIncompatible
+	}
+
+	for i := range f.bits {
+		f.bits[i] &= o.bits[i]
+	}
+
+	return nil
+}
+
+// compatible returns true if the filters have the same shape.
+func (f *Filter) compatible(o *Filter) bool {
+	return o != nil && f.m == o.m && f.k == o.k
+}
+
+// EstimatedCount returns an estimate of the number of distinct items
+// added to the filter, based on the number of bits set. A filter
+// with all of its bits set returns math.MaxInt.
+// O(m)
+func (f *Filter) EstimatedCount() int {
+	x := 0
+	for _, w := range f.bits {
+		x += bits.OnesCount64(w)
+	}
+
+	if uint64(x) >= f.m {
+		return math.MaxInt
+	}
+
+	m, k := float64(f.m), float64(f.k)
+	return int(math.Round(-m / k * math.Log(1-float64(x)/m)))
+}
+
+// Clear removes all the items from the filter.
+// O(m)
+func (f *Filter) Clear() {
+	clear(f.bits)
+}
+
+// MarshalBinary encodes the filter as a version byte, the number
+// of bits and hashes, followed by the bits, all in little endian.
+// O(m)
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 17+8*len(f.bits))
+	b = append(b, version)
+	b = binary.LittleEndian.AppendUint64(b, f.m)
+	b = binary.LittleEndian.AppendUint64(b, f.k)
+	for _, w := range f.bits {
+		b = binary.LittleEndian.AppendUint64(b, w)
+	}
+
+	return b, nil
+}
+
+// UnmarshalBinary replaces the filter with one encoded by MarshalBinary.
+// O(m)
+func (f *Filter) UnmarshalBinary(b []byte) error {
+	if len(b) < 17 || b[0] != version {
+		return ErrInvalidData
+	}
+
+	m := binary.LittleEndian.Uint64(b[1:])
+	k := binary.LittleEndian.Uint64(b[9:])
+	b = b[17:]
+
+	if m == 0 || k == 0 || k > maxHashes || m > math.MaxInt-63 || uint64(len(b)) != (m+63)/64*8 {
+		return ErrInvalidData
+	}
+
+	words := make([]uint64, len(b)/8)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(b[8*i:])
+	}
+
+	f.bits, f.m, f.k = words, m, k
+	return nil
+}
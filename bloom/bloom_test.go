@@ -0,0 +1,219 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	f := New(1000, 0.01)
+
+	// 1000 items at 1% need ~9586 bits and 7 hashes
+	if f.m != 9586 || f.k != 7 {
+		t.Errorf("Expected 9586 bits and 7 hashes, but got %v and %v", f.m, f.k)
+	}
+
+	for _, p := range []float64{0, 1, -0.5, 2} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Creating a filter with a rate of %v should have panicked", p)
+				}
+			}()
+
+			New(10, p)
+		}()
+	}
+}
+
+func TestAddMayContain(t *testing.T) {
+	f := New(100, 0.01)
+
+	for i := 0; i < 100; i++ {
+		if i%2 == 0 {
+			f.Add([]byte(fmt.Sprint(i)))
+		} else {
+			f.AddString(fmt.Sprint(i))
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		s := fmt.Sprint(i)
+		if !f.MayContain([]byte(s)) || !f.MayContainString(s) {
+			t.Errorf("Filter expected to contain %q, but did not", s)
+		}
+	}
+
+	f.Clear()
+	if f.MayContainString("1") {
+		t.Error("Filter should be empty after Clear")
+	}
+}
+
+func TestFalsePositiveRate(t *testing.T) {
+	const n, p = 10000, 0.01
+	f := New(n, p)
+
+	for i := 0; i < n; i++ {
+		f.AddString(fmt.Sprint("in", i))
+	}
+
+	fp := 0
+	for i := 0; i < n; i++ {
+		if f.MayContainString(fmt.Sprint("out", i)) {
+			fp++
+		}
+	}
+
+	// allow some slack over the target rate
+	if r := float64(fp) / n; r > 2*p {
+		t.Errorf("False positive rate expected to be around %v, but was %v", p, r)
+	}
+}
+
+func TestUnionIntersect(t *testing.T) {
+	a, b := New(100, 0.01), New(100, 0.01)
+	a.AddString("a")
+	a.AddString("both")
+	b.AddString("b")
+	b.AddString("both")
+
+	u := New(100, 0.01)
+	u.Union(a)
+	if err := u.Union(b); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"a", "b", "both"} {
+		if !u.MayContainString(s) {
+			t.Errorf("Union expected to contain %q, but did not", s)
+		}
+	}
+
+	if err := a.Intersect(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if !a.MayContainString("both") {
+		t.Errorf("Intersection expected to contain %q, but did not", "both")
+	}
+
+	if a.MayContainString("a") && a.MayContainString("b") {
+		t.Error("Intersection should not contain items from only one filter")
+	}
+
+	if err := a.Union(New(1000, 0.01)); err != ErrIncompatible {
+		t.Errorf("Expected %v, but got %v", ErrIncompatible, err)
+	}
+
+	if err := a.Intersect(NewWithSize(int(a.m), int(a.k)+1)); err != ErrIncompatible {
+		t.Errorf("Expected %v, but got %v", ErrIncompatible, err)
+	}
+
+	if err := a.Union(nil); err != ErrIncompatible {
+		t.Errorf("Expected %v, but got %v", ErrIncompatible, err)
+	}
+}
+
+func TestEstimatedCount(t *testing.T) {
+	f := New(10000, 0.01)
+
+	if c := f.EstimatedCount(); c != 0 {
+		t.Errorf("Empty filter expected to have 0 items, but has %v", c)
+	}
+
+	for i := 0; i < 5000; i++ {
+		f.AddString(fmt.Sprint(i))
+		// duplicates don't count
+		f.AddString(fmt.Sprint(i))
+	}
+
+	if c := f.EstimatedCount(); math.Abs(float64(c)-5000) > 250 {
+		t.Errorf("Filter expected to have about 5000 items, but has %v", c)
+	}
+
+	full := NewWithSize(64, 1)
+	for i := range full.bits {
+		full.bits[i] = math.MaxUint64
+	}
+
+	if c := full.EstimatedCount(); c != math.MaxInt {
+		t.Errorf("Saturated filter expected to report %v items, but has %v", math.MaxInt, c)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	f := New(500, 0.001)
+	for i := 0; i < 500; i++ {
+		f.AddString(fmt.Sprint(i))
+	}
+
+	b, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := new(Filter)
+	if err := g.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if g.m != f.m || g.k != f.k {
+		t.Errorf("Expected %v bits and %v hashes, but got %v and %v", f.m, f.k, g.m, g.k)
+	}
+
+	for i := 0; i < 500; i++ {
+		if !g.MayContainString(fmt.Sprint(i)) {
+			t.Errorf("Filter expected to contain %v, but did not", i)
+		}
+	}
+
+	if err := g.Union(f); err != nil {
+		t.Errorf("Unmarshaled filter should be compatible with the original: %v", err)
+	}
+
+	// too many hashes to have been marshaled by a filter
+	hashes := slices.Clone(b)
+	binary.LittleEndian.PutUint64(hashes[9:], 1<<60)
+
+	invalid := [][]byte{
+		nil,
+		{version},
+		append([]byte{version + 1}, b[1:]...),
+		b[:len(b)-1],
+		append(b, 0),
+		hashes,
+	}
+
+	for _, d := range invalid {
+		if err := new(Filter).UnmarshalBinary(d); err != ErrInvalidData {
+			t.Errorf("Expected %v for %v bytes, but got %v", ErrInvalidData, len(d), err)
+		}
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	f := New(b.N, 0.01)
+	d := []byte("benchmark")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d[0] = byte(i)
+		f.Add(d)
+	}
+}
+
+func BenchmarkMayContain(b *testing.B) {
+	f := New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.AddString(fmt.Sprint(i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.MayContainString("benchmark")
+	}
+}
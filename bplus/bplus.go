@@ -0,0 +1,476 @@
+// Package bplus implements an in-memory B+ tree.
+//
+// All the values are stored in the leaves, which are linked together so
+// ordered scans don't need to go back up the tree.
+package bplus
+
+import (
+	"cmp"
+	"errors"
+	"iter"
+	"slices"
+)
+
+// ErrUnsorted is returned when bulk loading keys that are not strictly increasing.
+var ErrUnsorted = errors.New("bplus: keys must be strictly increasing")
+
+// Tree is the internal representation of a B+ tree.
+// Trees must be created with New or NewFunc.
+type Tree[K, V any] struct {
+	root  *node[K, V]
+	count int
+	order int
+	cmp   func(a, b K) int
+}
+
+// node is the internal representation of a B+ tree node.
+// Internal nodes have one more child than keys, leaves have
+// one value per key and a link to the next leaf.
+type node[K, V any] struct {
+	keys     []K
+	vals     []V
+	children []*node[K, V]
+	next     *node[K, V]
+}
+
+// New returns an empty tree ordered by the natural ordering of its keys,
+// where every node has at most order children.
+// O(1)
+func New[K cmp.Ordered, V any](order int) *Tree[K, V] {
+	return NewFunc[K, V](order, cmp.Compare[K])
+}
+
+// NewFunc returns an empty tree ordered by the given comparator, where every
+// node has at most order children. The comparator must return a negative number
+// when a < b, a positive number when a > b and zero when a == b.
+// O(1)
+func NewFunc[K, V any](order int, cmp func(a, b K) int) *Tree[K, V] {
+	if order < 3 {
+		panic("B+ tree order must be at least 3")
+	}
+
+	return &Tree[K, V]{order: order, cmp: cmp}
+}
+
+// Insert adds a given key+value to the tree and returns true if it was added.
+// O(log(n))
+func (t *Tree[K, V]) Insert(k K, v V) bool {
+	if t.root == nil {
+		t.root = &node[K, V]{}
+	}
+
+	added, sk, sn := t.insert(t.root, k, v)
+	if sn != nil {
+		// the root was split, so the tree grows by one level
+		t.root = &node[K, V]{
+			keys:     []K{sk},
+			children: []*node[K, V]{t.root, sn},
+		}
+	}
+
+	if added {
+		t.count++
+	}
+
+	return added
+}
+
+// insert recursively adds a key+value under n. If n had to be split,
+// the new right sibling is returned with the key separating them.
+func (t *Tree[K, V]) insert(n *node[K, V], k K, v V) (added bool, sk K, sn *node[K, V]) {
+	if n.leaf() {
+		i, found := t.search(n.keys, k)
+		if found {
+			return false, sk, nil
+		}
+
+		n.keys = slices.Insert(n.keys, i, k)
+		n.vals = slices.Insert(n.vals, i, v)
+		if len(n.keys) < t.order {
+			return true, sk, nil
+		}
+
+		sk, sn = n.splitLeaf()
+		return true, sk, sn
+	}
+
+	i := t.child(n, k)
+	added, ck, cn := t.insert(n.children[i], k, v)
+	if cn == nil {
+		return added, sk, nil
+	}
+
+	n.keys = slices.Insert(n.keys, i, ck)
+	n.children = slices.Insert(n.children, i+1, cn)
+	if len(n.children) <= t.order {
+		return added, sk, nil
+	}
+
+	sk, sn = n.splitInternal()
+	return added, sk, sn
+}
+
+// splitLeaf moves the upper half of a leaf into a new leaf and returns
+// it with its first key, which becomes the separator in the parent.
+func (n *node[K, V]) splitLeaf() (K, *node[K, V]) {
+	m := len(n.keys) / 2
+	sn := &node[K, V]{
+		keys: slices.Clone(n.keys[m:]),
+		vals: slices.Clone(n.vals[m:]),
+		next: n.next,
+	}
+
+	clear(n.keys[m:])
+	clear(n.vals[m:])
+	n.keys = n.keys[:m]
+	n.vals = n.vals[:m]
+	n.next = sn
+
+	return sn.keys[0], sn
+}
+
+// splitInternal moves the upper half of an internal node into a new node
+// and returns it with the middle key, which moves up to the parent.
+func (n *node[K, V]) splitInternal() (K, *node[K, V]) {
+	m := len(n.keys) / 2
+	sk := n.keys[m]
+	sn := &node[K, V]{
+		keys:     slices.Clone(n.keys[m+1:]),
+		children: slices.Clone(n.children[m+1:]),
+	}
+
+	clear(n.keys[m:])
+	clear(n.children[m+1:])
+	n.keys = n.keys[:m]
+	n.children = n.children[:m+1]
+
+	return sk, sn
+}
+
+// Delete removes a given key from the tree and returns true if it was removed.
+// O(log(n))
+func (t *Tree[K, V]) Delete(k K) bool {
+	if t.root == nil || !t.delete(t.root, k) {
+		return false
+	}
+
+	// the root is allowed to underflow, but once it is an
+	// internal node with a single child the tree shrinks
+	if !t.root.leaf() && len(t.root.children) == 1 {
+		t.root = t.root.children[0]
+	}
+
+	t.count--
+	return true
+}
+
+// delete recursively removes a key under n, rebalancing any child
+// that is left with too few entries.
+func (t *Tree[K, V]) delete(n *node[K, V], k K) bool {
+	if n.leaf() {
+		i, found := t.search(n.keys, k)
+		if !found {
+			return false
+		}
+
+		n.keys = slices.Delete(n.keys, i, i+1)
+		n.vals = slices.Delete(n.vals, i, i+1)
+		return true
+	}
+
+	i := t.child(n, k)
+	if !t.delete(n.children[i], k) {
+		return false
+	}
+
+	if t.underflow(n.children[i]) {
+		t.rebalance(n, i)
+	}
+
+	return true
+}
+
+// rebalance fixes the underflowing child i of n, by borrowing an entry
+// from a sibling that can spare one, or merging it with a sibling.
+func (t *Tree[K, V]) rebalance(n *node[K, V], i int) {
+	c := n.children[i]
+
+	if i > 0 && t.spare(n.children[i-1]) {
+		l := n.children[i-1]
+		if c.leaf() {
+			j := len(l.keys) - 1
+			c.keys = slices.Insert(c.keys, 0, l.keys[j])
+			c.vals = slices.Insert(c.vals, 0, l.vals[j])
+			l.keys = slices.Delete(l.keys, j, j+1)
+			l.vals = slices.Delete(l.vals, j, j+1)
+			n.keys[i-1] = c.keys[0]
+		} else {
+			j := len(l.keys) - 1
+			c.keys = slices.Insert(c.keys, 0, n.keys[i-1])
+			c.children = slices.Insert(c.children, 0, l.children[j+1])
+			n.keys[i-1] = l.keys[j]
+			l.keys = slices.Delete(l.keys, j, j+1)
+			l.children = slices.Delete(l.children, j+1, j+2)
+		}
+
+		return
+	}
+
+	if i < len(n.children)-1 && t.spare(n.children[i+1]) {
+		r := n.children[i+1]
+		if c.leaf() {
+			c.keys = append(c.keys, r.keys[0])
+			c.vals = append(c.vals, r.vals[0])
+			r.keys = slices.Delete(r.keys, 0, 1)
+			r.vals = slices.Delete(r.vals, 0, 1)
+			n.keys[i] = r.keys[0]
+		} else {
+			c.keys = append(c.keys, n.keys[i])
+			c.children = append(c.children, r.children[0])
+			n.keys[i] = r.keys[0]
+			r.keys = slices.Delete(r.keys, 0, 1)
+			r.children = slices.Delete(r.children, 0, 1)
+		}
+
+		return
+	}
+
+	// neither sibling can spare an entry, so merge the
+	// child with one of them, always into the left node
+	if i == len(n.children)-1 {
+		i--
+	}
+
+	l, r := n.children[i], n.children[i+1]
+	if l.leaf() {
+		l.keys = append(l.keys, r.keys...)
+		l.vals = append(l.vals, r.vals...)
+		l.next = r.next
+	} else {
+		l.keys = append(append(l.keys, n.keys[i]), r.keys...)
+		l.children = append(l.children, r.children...)
+	}
+
+	n.keys = slices.Delete(n.keys, i, i+1)
+	n.children = slices.Delete(n.children, i+1, i+2)
+}
+
+// Find returns the value found at the given key and
+// true if the tree contains the key.
+// O(log(n))
+func (t *Tree[K, V]) Find(k K) (v V, found bool) {
+	if t.root == nil {
+		return v, false
+	}
+
+	n := t.root
+	for !n.leaf() {
+		n = n.children[t.child(n, k)]
+	}
+
+	i, found := t.search(n.keys, k)
+	if !found {
+		return v, false
+	}
+
+	return n.vals[i], true
+}
+
+// Len returns the number of elements in the tree.
+// O(1)
+func (t *Tree[K, V]) Len() int {
+	return t.count
+}
+
+// Clear removes all the nodes from the tree.
+// O(1)
+func (t *Tree[K, V]) Clear() {
+	t.root = nil
+	t.count = 0
+}
+
+// All returns an iterator over the key+value pairs of the tree
+// in ascending key order. The tree must not be modified while iterating.
+// O(n)
+func (t *Tree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if t.root == nil {
+			return
+		}
+
+		n := t.root
+		for !n.leaf() {
+			n = n.children[0]
+		}
+
+		scan(n, 0, yield)
+	}
+}
+
+// Range returns an iterator over the key+value pairs of the tree with
+// keys in [lo, hi), in ascending key order. The tree must not be modified
+// while iterating.
+// O(log(n)+m)
+func (t *Tree[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if t.root == nil {
+			return
+		}
+
+		n := t.root
+		for !n.leaf() {
+			n = n.children[t.child(n, lo)]
+		}
+
+		i, _ := t.search(n.keys, lo)
+		scan(n, i, func(k K, v V) bool {
+			return t.cmp(k, hi) < 0 && yield(k, v)
+		})
+	}
+}
+
+// scan yields the entries of the linked leaves, starting at index i of n.
+func scan[K, V any](n *node[K, V], i int, yield func(K, V) bool) {
+	for ; n != nil; n, i = n.next, 0 {
+		for ; i < len(n.keys); i++ {
+			if !yield(n.keys[i], n.vals[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Load replaces the contents of the tree with the given key+value pairs,
+// which must be in strictly increasing key order. The leaves are filled
+// completely, which makes loading much faster than repeated inserts.
+// If the keys are out of order, ErrUnsorted is returned and the tree
+// is left unchanged.
+// O(n)
+func (t *Tree[K, V]) Load(seq iter.Seq2[K, V]) error {
+	var leaves []*node[K, V]
+	var lows []K
+	var last K
+	count := 0
+
+	for k, v := range seq {
+		if count > 0 && t.cmp(last, k) >= 0 {
+			return ErrUnsorted
+		}
+
+		if len(leaves) == 0 || len(leaves[len(leaves)-1].keys) == t.order-1 {
+			l := &node[K, V]{
+				keys: make([]K, 0, t.order-1),
+				vals: make([]V, 0, t.order-1),
+			}
+			if len(leaves) > 0 {
+				leaves[len(leaves)-1].next = l
+			}
+			leaves = append(leaves, l)
+			lows = append(lows, k)
+		}
+
+		l := leaves[len(leaves)-1]
+		l.keys = append(l.keys, k)
+		l.vals = append(l.vals, v)
+		last = k
+		count++
+	}
+
+	if count == 0 {
+		t.Clear()
+		return nil
+	}
+
+	// only the last leaf may be short, in which case
+	// share the entries of the last two leaves evenly
+	if n := len(leaves); n > 1 && t.underflow(leaves[n-1]) {
+		l, r := leaves[n-2], leaves[n-1]
+		m := (len(l.keys) + len(r.keys)) / 2
+		r.keys = append(slices.Clone(l.keys[m:]), r.keys...)
+		r.vals = append(slices.Clone(l.vals[m:]), r.vals...)
+		clear(l.keys[m:])
+		clear(l.vals[m:])
+		l.keys = l.keys[:m]
+		l.vals = l.vals[:m]
+		lows[n-1] = r.keys[0]
+	}
+
+	// build the internal levels bottom up,
+	// until a single node is left as the root
+	level := leaves
+	for len(level) > 1 {
+		var parents []*node[K, V]
+		var plows []K
+
+		for i := 0; i < len(level); i += t.order {
+			j := min(i+t.order, len(level))
+			parents = append(parents, &node[K, V]{
+				keys:     slices.Clone(lows[i+1 : j]),
+				children: slices.Clone(level[i:j]),
+			})
+			plows = append(plows, lows[i])
+		}
+
+		if n := len(parents); n > 1 && t.underflow(parents[n-1]) {
+			// share the children of the last two nodes evenly
+			l, r := parents[n-2], parents[n-1]
+			c := len(l.children) + len(r.children)
+			m := len(level) - c + c/2
+			j := len(level) - c
+			l.keys = slices.Clone(lows[j+1 : m])
+			l.children = slices.Clone(level[j:m])
+			r.keys = slices.Clone(lows[m+1:])
+			r.children = slices.Clone(level[m:])
+			plows[n-1] = lows[m]
+		}
+
+		level, lows = parents, plows
+	}
+
+	t.root = level[0]
+	t.count = count
+
+	return nil
+}
+
+// leaf returns true if the node is a leaf.
+func (n *node[K, V]) leaf() bool {
+	return n.children == nil
+}
+
+// underflow returns true if a non-root node has too few entries.
+func (t *Tree[K, V]) underflow(n *node[K, V]) bool {
+	if n.leaf() {
+		return len(n.keys) < (t.order-1)/2
+	}
+
+	return len(n.children) < (t.order+1)/2
+}
+
+// spare returns true if a node can give an entry to a sibling
+// without underflowing.
+func (t *Tree[K, V]) spare(n *node[K, V]) bool {
+	if n.leaf() {
+		return len(n.keys) > (t.order-1)/2
+	}
+
+	return len(n.children) > (t.order+1)/2
+}
+
+// search returns the position of k in the sorted keys, or
+// the position where it would be inserted if it is missing.
+func (t *Tree[K, V]) search(keys []K, k K) (int, bool) {
+	return slices.BinarySearchFunc(keys, k, t.cmp)
+}
+
+// child returns the index of the child of an internal node
+// whose subtree would contain k.
+func (t *Tree[K, V]) child(n *node[K, V], k K) int {
+	i, found := t.search(n.keys, k)
+	if found {
+		// keys equal to a separator live in the right subtree
+		i++
+	}
+
+	return i
+}
@@ -0,0 +1,357 @@
+package bplus
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+var orders = []int{3, 4, 5, 16}
+
+func TestInsertFind(t *testing.T) {
+	for _, order := range orders {
+		tree := New[int, int](order)
+
+		for _, i := range rand.Perm(500) {
+			if !tree.Insert(i, i*10) {
+				t.Errorf("Order %v: element %v should have been added to the tree", order, i)
+			}
+		}
+		testInvariants(t, tree)
+
+		for i := 0; i < 500; i++ {
+			if v, ok := tree.Find(i); !ok || v != i*10 {
+				t.Errorf("Order %v: element %v expected to be in the tree, but was not", order, i)
+			}
+		}
+
+		if tree.Insert(4, 44) {
+			t.Errorf("Order %v: duplicate elements should not be added", order)
+		}
+
+		if _, ok := tree.Find(500); ok {
+			t.Errorf("Order %v: element %v should not have been found", order, 500)
+		}
+
+		if c := tree.Len(); c != 500 {
+			t.Errorf("Order %v: tree expected to have %v elements, but has %v instead", order, 500, c)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	for _, order := range orders {
+		tree := New[int, int](order)
+
+		if tree.Delete(1) {
+			t.Errorf("Order %v: deleting from an empty tree should fail", order)
+		}
+
+		for _, i := range rand.Perm(300) {
+			tree.Insert(i, i)
+		}
+
+		for j, i := range rand.Perm(300) {
+			if !tree.Delete(i) {
+				t.Fatalf("Order %v: element %v should have been removed", order, i)
+			}
+
+			if _, ok := tree.Find(i); ok {
+				t.Fatalf("Order %v: element %v should not have been found", order, i)
+			}
+
+			if tree.Delete(i) {
+				t.Fatalf("Order %v: element %v should not be deleted twice", order, i)
+			}
+
+			if c := tree.Len(); c != 300-j-1 {
+				t.Fatalf("Order %v: tree expected to have %v elements, but has %v", order, 300-j-1, c)
+			}
+			testInvariants(t, tree)
+		}
+	}
+}
+
+func TestRandomOperations(t *testing.T) {
+	for _, order := range orders {
+		tree := New[int, int](order)
+		m := make(map[int]bool)
+
+		for i := 0; i < 5000; i++ {
+			k := rand.Intn(300)
+			if rand.Intn(3) == 0 {
+				if tree.Delete(k) != m[k] {
+					t.Fatalf("Order %v: Delete(%v) disagrees with the expected contents", order, k)
+				}
+				delete(m, k)
+			} else {
+				if tree.Insert(k, k) == m[k] {
+					t.Fatalf("Order %v: Insert(%v) disagrees with the expected contents", order, k)
+				}
+				m[k] = true
+			}
+		}
+
+		if tree.Len() != len(m) {
+			t.Errorf("Order %v: tree expected to have %v elements, but has %v", order, len(m), tree.Len())
+		}
+		testInvariants(t, tree)
+	}
+}
+
+func TestRange(t *testing.T) {
+	tree := New[int, string](4)
+	for _, i := range rand.Perm(50) {
+		tree.Insert(i*2, fmt.Sprint(i*2))
+	}
+
+	tests := []struct {
+		lo, hi   int
+		expected []int
+	}{
+		{10, 17, []int{10, 12, 14, 16}},
+		{9, 16, []int{10, 12, 14}},
+		{-5, 3, []int{0, 2}},
+		{95, 200, []int{96, 98}},
+		{40, 40, nil},
+		{41, 42, nil},
+		{200, 300, nil},
+	}
+
+	for _, tt := range tests {
+		var keys []int
+		for k, v := range tree.Range(tt.lo, tt.hi) {
+			if v != fmt.Sprint(k) {
+				t.Errorf("Range(%v, %v) value for %v was %v", tt.lo, tt.hi, k, v)
+			}
+			keys = append(keys, k)
+		}
+
+		if !slices.Equal(keys, tt.expected) {
+			t.Errorf("Range(%v, %v) expected %v, but was %v", tt.lo, tt.hi, tt.expected, keys)
+		}
+	}
+
+	n := 0
+	for range tree.Range(0, 100) {
+		if n++; n == 3 {
+			break
+		}
+	}
+
+	if n != 3 {
+		t.Errorf("Expected to stop after %v elements, but scanned %v", 3, n)
+	}
+
+	for k := range New[int, int](3).Range(0, 1) {
+		t.Errorf("Scanning an empty tree should not find %v", k)
+	}
+}
+
+func TestAll(t *testing.T) {
+	tree := New[string, int](3)
+	words := []string{"kiwi", "apple", "fig", "banana", "cherry", "date", "elderberry"}
+	for i, w := range words {
+		tree.Insert(w, i)
+	}
+
+	var keys []string
+	for k := range tree.All() {
+		keys = append(keys, k)
+	}
+
+	if !slices.IsSorted(keys) || len(keys) != len(words) {
+		t.Errorf("Expected all the words in order, but was %v", keys)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for _, order := range orders {
+		for _, n := range []int{0, 1, 2, order - 1, order, order + 1, 97, 500} {
+			tree := New[int, int](order)
+			tree.Insert(-1, -1)
+
+			if err := tree.Load(sequence(n)); err != nil {
+				t.Fatalf("Order %v: loading %v elements failed: %v", order, n, err)
+			}
+
+			if c := tree.Len(); c != n {
+				t.Errorf("Order %v: tree expected to have %v elements, but has %v", order, n, c)
+			}
+			testInvariants(t, tree)
+
+			i := 0
+			for k, v := range tree.All() {
+				if k != i || v != i {
+					t.Errorf("Order %v: expected to scan %v, but scanned %v=%v", order, i, k, v)
+				}
+				i++
+			}
+
+			// the loaded tree must keep working as a regular tree
+			tree.Insert(n, n)
+			tree.Delete(0)
+			testInvariants(t, tree)
+		}
+	}
+}
+
+func TestLoad_Unsorted(t *testing.T) {
+	tree := New[int, int](4)
+	tree.Insert(1, 1)
+
+	err := tree.Load(func(yield func(int, int) bool) {
+		_ = yield(1, 1) && yield(3, 3) && yield(2, 2)
+	})
+
+	if err != ErrUnsorted {
+		t.Errorf("Expected %v, but got %v", ErrUnsorted, err)
+	}
+
+	if v, ok := tree.Find(1); !ok || v != 1 || tree.Len() != 1 {
+		t.Error("Tree should be unchanged after a failed load")
+	}
+}
+
+func TestNew_InvalidOrder(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Creating a tree of order 2 should have panicked")
+		}
+	}()
+
+	New[int, int](2)
+}
+
+// sequence returns n increasing key+value pairs.
+func sequence(n int) func(yield func(int, int) bool) {
+	return func(yield func(int, int) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(i, i) {
+				return
+			}
+		}
+	}
+}
+
+// testInvariants fails the test if the tree is not a valid B+ tree.
+func testInvariants[K, V any](t *testing.T, tree *Tree[K, V]) {
+	t.Helper()
+
+	if err := tree.check(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// check verifies the order, occupancy, depth and leaf links of the tree.
+func (t *Tree[K, V]) check() error {
+	if t.root == nil {
+		if t.count != 0 {
+			return fmt.Errorf("empty tree has a count of %v", t.count)
+		}
+		return nil
+	}
+
+	var leaves []*node[K, V]
+	depth := -1
+
+	var walk func(n *node[K, V], d int, lo, hi *K) error
+	walk = func(n *node[K, V], d int, lo, hi *K) error {
+		for i, k := range n.keys {
+			if lo != nil && t.cmp(k, *lo) < 0 || hi != nil && t.cmp(k, *hi) >= 0 {
+				return fmt.Errorf("key %v is outside of its parent's range", k)
+			}
+			if i > 0 && t.cmp(n.keys[i-1], k) >= 0 {
+				return fmt.Errorf("key %v is out of order", k)
+			}
+		}
+
+		if n != t.root && t.underflow(n) {
+			return fmt.Errorf("node with keys %v is underfull", n.keys)
+		}
+
+		if n.leaf() {
+			if len(n.keys) >= t.order || len(n.vals) != len(n.keys) {
+				return fmt.Errorf("leaf with keys %v has the wrong size", n.keys)
+			}
+			if depth != -1 && depth != d {
+				return fmt.Errorf("leaves are at depths %v and %v", depth, d)
+			}
+			depth = d
+			leaves = append(leaves, n)
+			return nil
+		}
+
+		if len(n.children) > t.order || len(n.children) != len(n.keys)+1 {
+			return fmt.Errorf("node with keys %v has %v children", n.keys, len(n.children))
+		}
+
+		for i, c := range n.children {
+			clo, chi := lo, hi
+			if i > 0 {
+				clo = &n.keys[i-1]
+			}
+			if i < len(n.keys) {
+				chi = &n.keys[i]
+			}
+			if err := walk(c, d+1, clo, chi); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := walk(t.root, 0, nil, nil); err != nil {
+		return err
+	}
+
+	count := 0
+	for i, l := range leaves {
+		count += len(l.keys)
+		if i < len(leaves)-1 && l.next != leaves[i+1] || i == len(leaves)-1 && l.next != nil {
+			return fmt.Errorf("leaf %v is not linked to the next leaf", i)
+		}
+	}
+
+	if count != t.count {
+		return fmt.Errorf("tree has %v keys, but a count of %v", count, t.count)
+	}
+
+	return nil
+}
+
+func BenchmarkInsert(b *testing.B) {
+	tree := New[int, int](64)
+	for _, i := range rand.Perm(b.N) {
+		tree.Insert(i, i)
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	tree := New[int, int](64)
+	tree.Load(sequence(b.N))
+}
+
+func BenchmarkFind(b *testing.B) {
+	tree := New[int, int](64)
+	tree.Load(sequence(b.N))
+
+	b.ResetTimer()
+	for _, i := range rand.Perm(b.N) {
+		tree.Find(i)
+	}
+}
+
+func BenchmarkRange(b *testing.B) {
+	tree := New[int, int](64)
+	tree.Load(sequence(1 << 16))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lo := i % (1 << 16)
+		for range tree.Range(lo, lo+100) {
+		}
+	}
+}
@@ -1,195 +1,963 @@
 // Package bst implements an unbalanced binary search tree.
 package bst
 
-// T is the internal representation of a binary search tree.
-type T struct {
-	root  *node
+import (
+	"cmp"
+	"iter"
+
+	"github.com/cosn/collections/queue"
+	"github.com/cosn/collections/stack"
+)
+
+// Tree is the internal representation of a binary search tree.
+// Trees must be created with New or NewFunc.
+type Tree[K, V any] struct {
+	root  *node[K, V]
 	count int
+	cmp   func(a, b K) int
 }
 
 // node is the internal representation of a binary tree node.
-type node struct {
-	key  int
-	val  interface{}
-	l, r *node
+type node[K, V any] struct {
+	key  K
+	val  V
+	l, r *node[K, V]
+	// size is the number of nodes in the subtree rooted at the node
+	size int
 }
 
-// TraversalType represents one of the three know traversals.
+// TraversalType represents one of the known traversals.
 type TraversalType int
 
 const (
 	InOrder TraversalType = iota
 	PreOrder
 	PostOrder
+	// LevelOrder visits the nodes breadth first,
+	// from the root down and from left to right
+	LevelOrder
 )
 
+// New returns an empty tree ordered by the natural ordering of its keys.
+// O(1)
+func New[K cmp.Ordered, V any]() *Tree[K, V] {
+	return NewFunc[K, V](cmp.Compare[K])
+}
+
+// NewFunc returns an empty tree ordered by the given comparator, which must
+// return a negative number when a < b, a positive number when a > b and
+// zero when a == b.
+// O(1)
+func NewFunc[K, V any](cmp func(a, b K) int) *Tree[K, V] {
+	return &Tree[K, V]{cmp: cmp}
+}
+
 // Insert adds a given key+value to the tree and returns true if it was added.
 // Average: O(log(n)) Worst: O(n)
-func (t *T) Insert(k int, v interface{}) (added bool) {
-	t.root, added = insert(t.root, k, v)
-	if added {
-		t.count++
+func (t *Tree[K, V]) Insert(k K, v V) bool {
+	l := &t.root
+	for *l != nil {
+		n := *l
+		c := t.cmp(k, n.key)
+		if c == 0 {
+			// the key already exists, so undo the sizes updated on the way
+			for m := t.root; m != n; m = m.child(t.cmp(k, m.key)) {
+				m.size--
+			}
+			return false
+		}
+
+		n.size++
+		if c < 0 {
+			l = &n.l
+		} else {
+			l = &n.r
+		}
+	}
+
+	*l = &node[K, V]{key: k, val: v, size: 1}
+	t.count++
+
+	return true
+}
+
+// Delete removes a given key from the tree and returns true if it was removed.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Delete(k K) bool {
+	l := &t.root
+	for *l != nil {
+		n := *l
+		c := t.cmp(k, n.key)
+		if c == 0 {
+			*l = remove(n)
+			t.count--
+			return true
+		}
+
+		n.size--
+		if c < 0 {
+			l = &n.l
+		} else {
+			l = &n.r
+		}
+	}
+
+	// the key doesn't exist, so undo the sizes updated on the way
+	for m := t.root; m != nil; m = m.child(t.cmp(k, m.key)) {
+		m.size++
+	}
+
+	return false
+}
+
+// Find returns the value found at the given key and
+// true if the tree contains the key.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Find(k K) (v V, found bool) {
+	for n := t.root; n != nil; {
+		c := t.cmp(k, n.key)
+		if c == 0 {
+			return n.val, true
+		}
+		n = n.child(c)
+	}
+
+	return v, false
+}
+
+// child returns the left child of a node if c is negative,
+// and the right child otherwise.
+func (n *node[K, V]) child(c int) *node[K, V] {
+	if c < 0 {
+		return n.l
+	}
+
+	return n.r
+}
+
+// Min returns the smallest key in the tree and its value.
+// The boolean is false if the tree is empty.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Min() (k K, v V, ok bool) {
+	n := t.root
+	if n == nil {
+		return k, v, false
+	}
+
+	for n.l != nil {
+		n = n.l
+	}
+
+	return n.key, n.val, true
+}
+
+// Max returns the largest key in the tree and its value.
+// The boolean is false if the tree is empty.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Max() (k K, v V, ok bool) {
+	n := t.root
+	if n == nil {
+		return k, v, false
+	}
+
+	for n.r != nil {
+		n = n.r
+	}
+
+	return n.key, n.val, true
+}
+
+// Floor returns the largest key in the tree less than or equal to k and its value.
+// The boolean is false if there is no such key.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Floor(k K) (K, V, bool) {
+	return result(t.below(k, true))
+}
+
+// Lower returns the largest key in the tree strictly less than k and its value.
+// The boolean is false if there is no such key.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Lower(k K) (K, V, bool) {
+	return result(t.below(k, false))
+}
+
+// Ceiling returns the smallest key in the tree greater than or equal to k and its value.
+// The boolean is false if there is no such key.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Ceiling(k K) (K, V, bool) {
+	return result(t.above(k, true))
+}
+
+// Higher returns the smallest key in the tree strictly greater than k and its value.
+// The boolean is false if there is no such key.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Higher(k K) (K, V, bool) {
+	return result(t.above(k, false))
+}
+
+// Predecessor returns the key that comes before k in the tree and its value.
+// The boolean is false if k is not in the tree or is the smallest key.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Predecessor(k K) (p K, v V, ok bool) {
+	if _, found := t.Find(k); !found {
+		return p, v, false
+	}
+
+	return t.Lower(k)
+}
+
+// Successor returns the key that comes after k in the tree and its value.
+// The boolean is false if k is not in the tree or is the largest key.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Successor(k K) (s K, v V, ok bool) {
+	if _, found := t.Find(k); !found {
+		return s, v, false
+	}
+
+	return t.Higher(k)
+}
+
+// below returns the node with the largest key less than k,
+// or equal to k if inclusive, or nil if there is no such node.
+func (t *Tree[K, V]) below(k K, inclusive bool) (f *node[K, V]) {
+	for n := t.root; n != nil; {
+		if c := t.cmp(k, n.key); c > 0 || c == 0 && inclusive {
+			f = n
+			n = n.r
+		} else {
+			n = n.l
+		}
 	}
 
 	return
 }
 
-// insert recusively adds a key+value in the tree.
-func insert(n *node, k int, v interface{}) (r *node, added bool) {
-	if r = n; n == nil {
-		// keep track of how many elements we have in the tree
-		// to optimize the channel length during traversal
-		r = &node{key: k, val: v}
-		added = true
-	} else if k < n.key {
-		r.l, added = insert(n.l, k, v)
-	} else if k > n.key {
-		r.r, added = insert(n.r, k, v)
+// above returns the node with the smallest key greater than k,
+// or equal to k if inclusive, or nil if there is no such node.
+func (t *Tree[K, V]) above(k K, inclusive bool) (f *node[K, V]) {
+	for n := t.root; n != nil; {
+		if c := t.cmp(k, n.key); c < 0 || c == 0 && inclusive {
+			f = n
+			n = n.l
+		} else {
+			n = n.r
+		}
 	}
 
 	return
 }
 
-// Delete removes a given key from the tree and returns true if it was removed.
+// result unpacks a node found by a query.
+func result[K, V any](n *node[K, V]) (k K, v V, ok bool) {
+	if n == nil {
+		return k, v, false
+	}
+
+	return n.key, n.val, true
+}
+
+// Rank returns the number of keys in the tree that are smaller than k.
 // Average: O(log(n)) Worst: O(n)
-func (t *T) Delete(k int) (deleted bool) {
-	n, deleted := delete(t.root, k)
-	if deleted {
-		// Handling the case of root deletion.
-		if t.root.key == k {
-			t.root = n
+func (t *Tree[K, V]) Rank(k K) int {
+	r := 0
+	for n := t.root; n != nil; {
+		if t.cmp(k, n.key) <= 0 {
+			n = n.l
+		} else {
+			r += size(n.l) + 1
+			n = n.r
 		}
+	}
 
-		t.count--
+	return r
+}
+
+// Select returns the key+value with the given rank, i.e. the i-th
+// smallest key counting from 0, and true if 0 <= i < Len().
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Select(i int) (k K, v V, ok bool) {
+	if i < 0 || i >= t.count {
+		return
 	}
 
-	return deleted
+	n := t.root
+	for {
+		if l := size(n.l); i < l {
+			n = n.l
+		} else if i > l {
+			i -= l + 1
+			n = n.r
+		} else {
+			return n.key, n.val, true
+		}
+	}
 }
 
-// delete recursively deletes a key from the tree.
-func delete(n *node, k int) (r *node, deleted bool) {
-	if r = n; n == nil {
-		return nil, false
+// CountRange returns the number of keys k in the tree such that lo <= k < hi.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) CountRange(lo, hi K) int {
+	return max(t.Rank(hi)-t.Rank(lo), 0)
+}
+
+// size returns the number of nodes in a subtree.
+func size[K, V any](n *node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+
+	return n.size
+}
+
+// Range returns an iterator over the key+value pairs of the tree with keys
+// between lo and hi, in ascending key order. Each bound is included if its
+// flag is true. Subtrees outside of the bounds are never visited.
+// Average: O(log(n)+m) Worst: O(n) where m is the number of keys in range
+func (t *Tree[K, V]) Range(lo, hi K, loInclusive, hiInclusive bool) iter.Seq2[K, V] {
+	b := bounds[K]{lo, hi, loInclusive, hiInclusive}
+	return func(yield func(K, V) bool) {
+		t.ascend(t.root, b, yield)
+	}
+}
+
+// RangeDesc returns an iterator over the key+value pairs of the tree with keys
+// between lo and hi, in descending key order. Each bound is included if its
+// flag is true. Subtrees outside of the bounds are never visited.
+// Average: O(log(n)+m) Worst: O(n) where m is the number of keys in range
+func (t *Tree[K, V]) RangeDesc(lo, hi K, loInclusive, hiInclusive bool) iter.Seq2[K, V] {
+	b := bounds[K]{lo, hi, loInclusive, hiInclusive}
+	return func(yield func(K, V) bool) {
+		t.descend(t.root, b, yield)
 	}
+}
 
-	if k < n.key {
-		r.l, deleted = delete(n.l, k)
-	} else if k > n.key {
-		r.r, deleted = delete(n.r, k)
-	} else {
-		if n.l != nil && n.r != nil {
-			// find the right most element in the left subtree
-			s := n.l
-			for s.r != nil {
-				s = s.r
+// bounds are the limits of a range of keys.
+type bounds[K any] struct {
+	lo, hi                   K
+	loInclusive, hiInclusive bool
+}
+
+// check compares a key to the bounds, returning whether keys smaller
+// and larger than it may be in range, and whether it is in range itself.
+func (t *Tree[K, V]) check(k K, b bounds[K]) (left, right, in bool) {
+	l, h := t.cmp(k, b.lo), t.cmp(k, b.hi)
+	in = (l > 0 || l == 0 && b.loInclusive) && (h < 0 || h == 0 && b.hiInclusive)
+
+	return l > 0, h < 0, in
+}
+
+// checked is a node on the path of a range, along with the result
+// of checking its key against the bounds.
+type checked[K, V any] struct {
+	n               *node[K, V]
+	left, right, in bool
+}
+
+// ascend yields the nodes that are within the bounds in ascending order.
+// It returns false once yield asks to stop.
+func (t *Tree[K, V]) ascend(n *node[K, V], b bounds[K], yield func(K, V) bool) bool {
+	var s stack.Stack[checked[K, V]]
+
+	for {
+		// follow the left children while smaller keys may be in range
+		for n != nil {
+			left, right, in := t.check(n.key, b)
+			s.Push(checked[K, V]{n, left, right, in})
+			if !left {
+				break
 			}
-			r.key = s.key
-			r.val = s.val
-			r.l, deleted = delete(s, s.key)
-		} else if n.l != nil {
-			r = n.l
-			deleted = true
-		} else if n.r != nil {
-			r = n.r
-			deleted = true
+			n = n.l
+		}
+
+		c, ok := s.Pop()
+		if !ok {
+			return true
+		}
+
+		if c.in && !yield(c.n.key, c.n.val) {
+			return false
+		}
+
+		n = nil
+		if c.right {
+			n = c.n.r
+		}
+	}
+}
+
+// descend yields the nodes that are within the bounds in descending order.
+// It returns false once yield asks to stop.
+func (t *Tree[K, V]) descend(n *node[K, V], b bounds[K], yield func(K, V) bool) bool {
+	var s stack.Stack[checked[K, V]]
+
+	for {
+		// follow the right children while larger keys may be in range
+		for n != nil {
+			left, right, in := t.check(n.key, b)
+			s.Push(checked[K, V]{n, left, right, in})
+			if !right {
+				break
+			}
+			n = n.r
+		}
+
+		c, ok := s.Pop()
+		if !ok {
+			return true
+		}
+
+		if c.in && !yield(c.n.key, c.n.val) {
+			return false
+		}
+
+		n = nil
+		if c.left {
+			n = c.n.l
+		}
+	}
+}
+
+// DeleteRange removes the keys k such that lo <= k < hi from the tree
+// and returns the number of keys removed.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) DeleteRange(lo, hi K) int {
+	// cut out the subtree of keys in range, and join what's left around it
+	l, r := t.split(t.root, lo)
+	m, r := t.split(r, hi)
+	t.root = join(l, r)
+
+	removed := size(m)
+	t.count -= removed
+
+	return removed
+}
+
+// remove returns the subtree of n without n itself, replacing n with
+// the right most node of its left subtree if it has two children.
+func remove[K, V any](n *node[K, V]) *node[K, V] {
+	if n.l == nil {
+		return n.r
+	}
+
+	if n.r == nil {
+		return n.l
+	}
+
+	l, p := removeMax(n.l)
+	p.l, p.r = l, n.r
+	p.size = size(p.l) + size(p.r) + 1
+
+	return p
+}
+
+// removeMax detaches the right most node of a subtree and
+// returns the rest of the subtree and that node.
+func removeMax[K, V any](n *node[K, V]) (*node[K, V], *node[K, V]) {
+	l := &n
+	for (*l).r != nil {
+		(*l).size--
+		l = &(*l).r
+	}
+
+	m := *l
+	*l = m.l
+
+	return n, m
+}
+
+// Split divides the tree around a key, returning a tree with the keys
+// smaller than k and a tree with the keys greater than or equal to k.
+// The nodes are moved, not copied, so the tree is left empty.
+// Average: O(log(n)) Worst: O(n)
+func (t *Tree[K, V]) Split(k K) (left, right *Tree[K, V]) {
+	l, r := t.split(t.root, k)
+	t.root = nil
+	t.count = 0
+
+	return &Tree[K, V]{l, size(l), t.cmp}, &Tree[K, V]{r, size(r), t.cmp}
+}
+
+// split divides a subtree into the nodes with keys smaller than k
+// and the nodes with keys greater than or equal to k.
+func (t *Tree[K, V]) split(n *node[K, V], k K) (l, r *node[K, V]) {
+	// the links where the next nodes of each side are attached
+	ll, rl := &l, &r
+	var path stack.Stack[*node[K, V]]
+
+	for n != nil {
+		path.Push(n)
+		if t.cmp(n.key, k) < 0 {
+			*ll = n
+			ll = &n.r
+			n = n.r
 		} else {
-			r = nil
-			deleted = true
+			*rl = n
+			rl = &n.l
+			n = n.l
 		}
 	}
+	*ll, *rl = nil, nil
+
+	// the nodes on the path lost or gained children,
+	// so fix their sizes from the bottom up
+	for n, ok := path.Pop(); ok; n, ok = path.Pop() {
+		n.size = size(n.l) + size(n.r) + 1
+	}
 
 	return
 }
 
-// Find returns the value found at the given key.
+// Join moves all the keys of o into this tree and returns true, as long as
+// they are all greater than the keys in this tree. Otherwise false is
+// returned and both trees keep their keys. The nodes are moved, not copied.
 // Average: O(log(n)) Worst: O(n)
-func (t *T) Find(k int) interface{} {
-	return find(t.root, k)
-}
+func (t *Tree[K, V]) Join(o *Tree[K, V]) bool {
+	if o == nil || o.root == nil {
+		return true
+	}
 
-func find(n *node, k int) interface{} {
-	if n == nil {
-		return nil
+	if t.root != nil {
+		maxKey, _, _ := t.Max()
+		minKey, _, _ := o.Min()
+		if t.cmp(maxKey, minKey) >= 0 {
+			return false
+		}
 	}
 
-	if n.key == k {
-		return n.val
-	} else if k < n.key {
-		return find(n.l, k)
-	} else if k > n.key {
-		return find(n.r, k)
+	t.root = join(t.root, o.root)
+	t.count += o.count
+	o.root = nil
+	o.count = 0
+
+	return true
+}
+
+// join combines two subtrees, where all the keys in l are smaller than
+// the keys in r. The largest node of l becomes the root, with the rest
+// of l on its left and r on its right.
+func join[K, V any](l, r *node[K, V]) *node[K, V] {
+	if l == nil {
+		return r
 	}
 
-	return nil
+	l, m := removeMax(l)
+	m.l, m.r = l, r
+	m.size = size(l) + size(r) + 1
+
+	return m
+}
+
+// Len returns the number of elements in the tree.
+// O(1)
+func (t *Tree[K, V]) Len() int {
+	return t.count
 }
 
 // Clear removes all the nodes from the tree.
-// O(n)
-func (t *T) Clear() {
-	t.root = clear(t.root)
+// O(1)
+func (t *Tree[K, V]) Clear() {
+	t.root = nil
 	t.count = 0
 }
 
-// clear recursively removes all the nodes.
-func clear(n *node) *node {
-	if n != nil {
-		n.l = clear(n.l)
-		n.r = clear(n.r)
+// Traverse provides an iterator over the values of the tree.
+// The values are collected before returning, so the channel may be
+// abandoned at any point. New code should prefer Walk.
+// O(n)
+func (t *Tree[K, V]) Traverse(tt TraversalType) <-chan V {
+	c := make(chan V, t.count)
+	for _, v := range t.Walk(tt) {
+		c <- v
 	}
-	n = nil
+	close(c)
 
-	return n
+	return c
 }
 
-// Traverse provides an iterator over the tree.
+// Walk returns an iterator over the key+value pairs of the tree
+// in the order given by the traversal type.
 // O(n)
-func (t *T) Traverse(tt TraversalType) <-chan interface{} {
-	c := make(chan interface{}, t.count)
-	go func() {
+func (t *Tree[K, V]) Walk(tt TraversalType) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
 		switch tt {
-
 		case InOrder:
-			inOrder(t.root, c)
+			inOrder(t.root, yield)
 		case PreOrder:
-			preOrder(t.root, c)
+			preOrder(t.root, yield)
 		case PostOrder:
-			postOrder(t.root, c)
+			postOrder(t.root, yield)
+		case LevelOrder:
+			breadthFirst(t.root, func(_ int, n *node[K, V]) bool {
+				return yield(n.key, n.val)
+			})
 		}
-		close(c)
-	}()
+	}
+}
 
-	return c
+// All returns an iterator over the key+value pairs of the tree
+// in ascending key order.
+// O(n)
+func (t *Tree[K, V]) All() iter.Seq2[K, V] {
+	return t.Walk(InOrder)
 }
 
-// inOrder returns the left, parent, right nodes.
-func inOrder(n *node, c chan interface{}) {
-	if n == nil {
-		return
+// Backward returns an iterator over the key+value pairs of the tree
+// in descending key order.
+// O(n)
+func (t *Tree[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		reverseOrder(t.root, yield)
 	}
+}
 
-	inOrder(n.l, c)
-	c <- n.val
-	inOrder(n.r, c)
+// Keys returns an iterator over the keys of the tree in ascending order.
+// O(n)
+func (t *Tree[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range t.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
 }
 
-// preOrder returns the parent, left, right nodes.
-func preOrder(n *node, c chan interface{}) {
-	if n == nil {
-		return
+// Values returns an iterator over the values of the tree in ascending key order.
+// O(n)
+func (t *Tree[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range t.All() {
+			if !yield(v) {
+				return
+			}
+		}
 	}
+}
+
+// Levels returns an iterator over the keys at each depth of the tree,
+// from the root down, with the keys of each level in ascending order.
+// O(n)
+func (t *Tree[K, V]) Levels() iter.Seq[[]K] {
+	return func(yield func([]K) bool) {
+		var level []K
+		depth := 0
+
+		ok := breadthFirst(t.root, func(d int, n *node[K, V]) bool {
+			if d != depth {
+				if !yield(level) {
+					return false
+				}
+				level, depth = nil, d
+			}
+			level = append(level, n.key)
+			return true
+		})
 
-	c <- n.val
-	preOrder(n.l, c)
-	preOrder(n.r, c)
+		// the last level is complete once the walk is over
+		if ok && len(level) > 0 {
+			yield(level)
+		}
+	}
 }
 
-// postOrder returns the left, right, parent nodes.
-func postOrder(n *node, c chan interface{}) {
-	if n == nil {
-		return
+// Height returns the number of levels of the tree, which is 0 if it's empty.
+// O(n)
+func (t *Tree[K, V]) Height() int {
+	return len(t.LevelCounts())
+}
+
+// LevelCounts returns the number of nodes at each depth of the tree,
+// from the root down.
+// O(n)
+func (t *Tree[K, V]) LevelCounts() (counts []int) {
+	breadthFirst(t.root, func(d int, _ *node[K, V]) bool {
+		if d == len(counts) {
+			counts = append(counts, 0)
+		}
+		counts[d]++
+		return true
+	})
+
+	return
+}
+
+// leveled is a node queued by a breadth first walk, along with its depth.
+type leveled[K, V any] struct {
+	n     *node[K, V]
+	depth int
+}
+
+// breadthFirst yields the nodes level by level and their depth.
+// It returns false once yield asks to stop.
+func breadthFirst[K, V any](n *node[K, V], yield func(int, *node[K, V]) bool) bool {
+	var q queue.Queue[leveled[K, V]]
+	if n != nil {
+		q.Push(leveled[K, V]{n, 0})
+	}
+
+	for e, ok := q.Pop(); ok; e, ok = q.Pop() {
+		if !yield(e.depth, e.n) {
+			return false
+		}
+
+		if e.n.l != nil {
+			q.Push(leveled[K, V]{e.n.l, e.depth + 1})
+		}
+		if e.n.r != nil {
+			q.Push(leveled[K, V]{e.n.r, e.depth + 1})
+		}
+	}
+
+	return true
+}
+
+// inOrder yields the left, parent, right nodes.
+// It returns false once yield asks to stop.
+func inOrder[K, V any](n *node[K, V], yield func(K, V) bool) bool {
+	var s stack.Stack[*node[K, V]]
+
+	for {
+		for ; n != nil; n = n.l {
+			s.Push(n)
+		}
+
+		var ok bool
+		if n, ok = s.Pop(); !ok {
+			return true
+		}
+
+		if !yield(n.key, n.val) {
+			return false
+		}
+		n = n.r
 	}
+}
+
+// reverseOrder yields the right, parent, left nodes.
+func reverseOrder[K, V any](n *node[K, V], yield func(K, V) bool) bool {
+	var s stack.Stack[*node[K, V]]
+
+	for {
+		for ; n != nil; n = n.r {
+			s.Push(n)
+		}
+
+		var ok bool
+		if n, ok = s.Pop(); !ok {
+			return true
+		}
+
+		if !yield(n.key, n.val) {
+			return false
+		}
+		n = n.l
+	}
+}
+
+// preOrder yields the parent, left, right nodes.
+func preOrder[K, V any](n *node[K, V], yield func(K, V) bool) bool {
+	var s stack.Stack[*node[K, V]]
+	if n != nil {
+		s.Push(n)
+	}
+
+	for n, ok := s.Pop(); ok; n, ok = s.Pop() {
+		if !yield(n.key, n.val) {
+			return false
+		}
+
+		// the right child is pushed first, so the left one comes out first
+		if n.r != nil {
+			s.Push(n.r)
+		}
+		if n.l != nil {
+			s.Push(n.l)
+		}
+	}
+
+	return true
+}
+
+// postOrder yields the left, right, parent nodes.
+func postOrder[K, V any](n *node[K, V], yield func(K, V) bool) bool {
+	var s stack.Stack[*node[K, V]]
+	// last is the previous node yielded, which tells whether
+	// the right subtree of the node on top was already visited
+	var last *node[K, V]
+
+	for {
+		for ; n != nil; n = n.l {
+			s.Push(n)
+		}
+
+		p, ok := s.Peek()
+		if !ok {
+			return true
+		}
+
+		if p.r != nil && p.r != last {
+			n = p.r
+			continue
+		}
+
+		if !yield(p.key, p.val) {
+			return false
+		}
+		last = p
+		s.Pop()
+	}
+}
+
+// T is a binary search tree keyed by int.
+// It is kept for compatibility, new code should use Tree.
+// The zero value is an empty tree ready to use.
+type T struct {
+	Tree[int, interface{}]
+}
+
+// tree returns the underlying tree, setting up its comparator
+// the first time it is used.
+func (t *T) tree() *Tree[int, interface{}] {
+	if t.cmp == nil {
+		t.cmp = cmp.Compare[int]
+	}
+
+	return &t.Tree
+}
+
+// Insert adds a given key+value to the tree and returns true if it was added.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Insert(k int, v interface{}) bool {
+	return t.tree().Insert(k, v)
+}
+
+// Delete removes a given key from the tree and returns true if it was removed.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Delete(k int) bool {
+	return t.tree().Delete(k)
+}
+
+// Find returns the value found at the given key.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Find(k int) interface{} {
+	v, _ := t.tree().Find(k)
+	return v
+}
+
+// Rank returns the number of keys in the tree that are smaller than k.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Rank(k int) int {
+	return t.tree().Rank(k)
+}
+
+// CountRange returns the number of keys k in the tree such that lo <= k < hi.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) CountRange(lo, hi int) int {
+	return t.tree().CountRange(lo, hi)
+}
+
+// Floor returns the largest key in the tree less than or equal to k and its value.
+// The boolean is false if there is no such key.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Floor(k int) (int, interface{}, bool) {
+	return t.tree().Floor(k)
+}
+
+// Lower returns the largest key in the tree strictly less than k and its value.
+// The boolean is false if there is no such key.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Lower(k int) (int, interface{}, bool) {
+	return t.tree().Lower(k)
+}
+
+// Ceiling returns the smallest key in the tree greater than or equal to k and its value.
+// The boolean is false if there is no such key.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Ceiling(k int) (int, interface{}, bool) {
+	return t.tree().Ceiling(k)
+}
+
+// Higher returns the smallest key in the tree strictly greater than k and its value.
+// The boolean is false if there is no such key.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Higher(k int) (int, interface{}, bool) {
+	return t.tree().Higher(k)
+}
+
+// Predecessor returns the key that comes before k in the tree and its value.
+// The boolean is false if k is not in the tree or is the smallest key.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Predecessor(k int) (int, interface{}, bool) {
+	return t.tree().Predecessor(k)
+}
+
+// Successor returns the key that comes after k in the tree and its value.
+// The boolean is false if k is not in the tree or is the largest key.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Successor(k int) (int, interface{}, bool) {
+	return t.tree().Successor(k)
+}
+
+// Range returns an iterator over the key+value pairs of the tree with keys
+// between lo and hi, in ascending key order. Each bound is included if its
+// flag is true.
+// Average: O(log(n)+m) Worst: O(n) where m is the number of keys in range
+func (t *T) Range(lo, hi int, loInclusive, hiInclusive bool) iter.Seq2[int, interface{}] {
+	return t.tree().Range(lo, hi, loInclusive, hiInclusive)
+}
+
+// RangeDesc returns an iterator over the key+value pairs of the tree with keys
+// between lo and hi, in descending key order. Each bound is included if its
+// flag is true.
+// Average: O(log(n)+m) Worst: O(n) where m is the number of keys in range
+func (t *T) RangeDesc(lo, hi int, loInclusive, hiInclusive bool) iter.Seq2[int, interface{}] {
+	return t.tree().RangeDesc(lo, hi, loInclusive, hiInclusive)
+}
+
+// DeleteRange removes the keys k such that lo <= k < hi from the tree
+// and returns the number of keys removed.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) DeleteRange(lo, hi int) int {
+	return t.tree().DeleteRange(lo, hi)
+}
+
+// Split divides the tree around a key, returning a tree with the keys
+// smaller than k and a tree with the keys greater than or equal to k.
+// The nodes are moved, not copied, so the tree is left empty.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Split(k int) (left, right *T) {
+	l, r := t.tree().Split(k)
+	return &T{*l}, &T{*r}
+}
+
+// Join moves all the keys of o into this tree and returns true, as long as
+// they are all greater than the keys in this tree. Otherwise false is
+// returned and both trees keep their keys. The nodes are moved, not copied.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) Join(o *T) bool {
+	if o == nil {
+		return true
+	}
+
+	return t.tree().Join(o.tree())
+}
+
+// Join returns a tree with the keys of a followed by the keys of b, and true,
+// as long as the keys of b are all greater than the keys of a. The nodes
+// are moved, not copied, so a and b are left empty like after Split.
+// Otherwise nil and false are returned and both trees keep their keys.
+// Either tree may be nil.
+// Average: O(log(n)) Worst: O(n)
+func Join(a, b *T) (*T, bool) {
+	if a == nil {
+		a = new(T)
+	}
+
+	if !a.Join(b) {
+		return nil, false
+	}
+
+	j := &T{a.Tree}
+	a.Clear()
 
-	postOrder(n.l, c)
-	postOrder(n.r, c)
-	c <- n.val
+	return j, true
 }
@@ -3,6 +3,8 @@ package bst
 import (
 	"fmt"
 	"math/rand"
+	"runtime/debug"
+	"slices"
 	"testing"
 )
 
@@ -188,6 +190,153 @@ func TestTraverse_PostOrder(t *testing.T) {
 	}
 }
 
+func TestTraverse_LevelOrder(t *testing.T) {
+	elements := []int{5, 3, 7, 4, 6, 1, 9, 8}
+	expected := []int{5, 3, 7, 1, 4, 6, 9, 8}
+
+	bst := new(T)
+
+	for _, i := range elements {
+		bst.Insert(i, i)
+	}
+
+	i := 0
+	for e := range bst.Traverse(LevelOrder) {
+		if e != expected[i] {
+			t.Errorf("Expected to traverse %v, but instead traversed %v", expected[i], e)
+		}
+		i++
+	}
+
+	if i != len(expected) {
+		t.Errorf("Expected %v elements, but traversed %v", len(expected), i)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	bst := new(T)
+
+	if h := bst.Height(); h != 0 {
+		t.Errorf("Empty tree expected to have a height of 0, but was %v", h)
+	}
+
+	for range bst.Levels() {
+		t.Error("Empty tree should not have any levels")
+	}
+
+	for _, i := range []int{5, 3, 7, 4, 6, 1, 9, 8} {
+		bst.Insert(i, i)
+	}
+
+	expected := [][]int{{5}, {3, 7}, {1, 4, 6, 9}, {8}}
+	levels := slices.Collect(bst.Levels())
+
+	if !slices.EqualFunc(levels, expected, slices.Equal) {
+		t.Errorf("Expected levels %v, but were %v", expected, levels)
+	}
+
+	if h := bst.Height(); h != 4 {
+		t.Errorf("Tree expected to have a height of %v, but was %v", 4, h)
+	}
+
+	if c := bst.LevelCounts(); !slices.Equal(c, []int{1, 2, 4, 1}) {
+		t.Errorf("Expected level counts %v, but were %v", []int{1, 2, 4, 1}, c)
+	}
+
+	n := 0
+	for range bst.Levels() {
+		if n++; n == 2 {
+			break
+		}
+	}
+
+	if n != 2 {
+		t.Errorf("Expected to stop after %v levels, but found %v", 2, n)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	elements := []int{5, 3, 7, 4, 6}
+	tests := []struct {
+		tt       TraversalType
+		expected []int
+	}{
+		{InOrder, []int{3, 4, 5, 6, 7}},
+		{PreOrder, []int{5, 3, 4, 7, 6}},
+		{LevelOrder, []int{5, 3, 7, 4, 6}},
+		{PostOrder, []int{4, 3, 6, 7, 5}},
+	}
+
+	bst := New[int, int]()
+	for _, i := range elements {
+		bst.Insert(i, i*10)
+	}
+
+	for _, tt := range tests {
+		i := 0
+		for k, v := range bst.Walk(tt.tt) {
+			if k != tt.expected[i] || v != k*10 {
+				t.Errorf("Traversal %v expected %v, but instead traversed %v=%v", tt.tt, tt.expected[i], k, v)
+			}
+			i++
+		}
+
+		if i != len(tt.expected) {
+			t.Errorf("Traversal %v expected %v elements, but traversed %v", tt.tt, len(tt.expected), i)
+		}
+	}
+}
+
+func TestAllBackwardKeysValues(t *testing.T) {
+	bst := New[int, string]()
+	for _, i := range []int{5, 3, 7, 4, 6} {
+		bst.Insert(i, fmt.Sprint(i))
+	}
+
+	var keys, backward []int
+	var values []string
+	for k := range bst.Keys() {
+		keys = append(keys, k)
+	}
+	for v := range bst.Values() {
+		values = append(values, v)
+	}
+	for k := range bst.Backward() {
+		backward = append(backward, k)
+	}
+
+	if fmt.Sprint(keys) != "[3 4 5 6 7]" {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+	if fmt.Sprint(values) != "[3 4 5 6 7]" {
+		t.Errorf("Unexpected values %v", values)
+	}
+	if fmt.Sprint(backward) != "[7 6 5 4 3]" {
+		t.Errorf("Unexpected backward keys %v", backward)
+	}
+}
+
+func TestAll_EarlyBreak(t *testing.T) {
+	bst := new(T)
+	for _, i := range rand.Perm(100) {
+		bst.Insert(i, i)
+	}
+
+	i := 0
+	for k := range bst.All() {
+		if k != i {
+			t.Errorf("Expected to traverse %v, but instead traversed %v", i, k)
+		}
+		if i++; i == 10 {
+			break
+		}
+	}
+
+	if i != 10 {
+		t.Errorf("Expected to stop after %v elements, but traversed %v", 10, i)
+	}
+}
+
 func TestClear(t *testing.T) {
 	elements := []int{5, 3, 7, 4, 6}
 
@@ -208,12 +357,585 @@ func TestClear(t *testing.T) {
 	}
 }
 
-func (t *T) String() (s string) {
+func TestRemove_TwoChildrenDeepPredecessor(t *testing.T) {
+	bst := new(T)
+
+	// the predecessor of 5 is 4, which is not the direct left child
+	for _, i := range []int{5, 2, 7, 1, 3, 4} {
+		bst.Insert(i, i)
+	}
+
+	if !bst.Delete(5) {
+		t.Errorf("Element %v should have been removed", 5)
+	}
+
+	for _, i := range []int{1, 2, 3, 4, 7} {
+		if bst.Find(i) == nil {
+			t.Errorf("Element with key %v was not found", i)
+		}
+	}
+
+	if bst.count != 5 {
+		t.Errorf("Expected element count %v found cound %v", 5, bst.count)
+	}
+	testSizes(t, &bst.Tree)
+}
+
+func TestTree_OrderedKeys(t *testing.T) {
+	tree := New[string, int]()
+
+	for i, k := range []string{"m", "c", "x", "a", "e"} {
+		if !tree.Insert(k, i) {
+			t.Errorf("Element %v should have been added to the tree", k)
+		}
+	}
+
+	if v, ok := tree.Find("x"); !ok || v != 2 {
+		t.Errorf("Expected to find %v at key %v, found %v (%t)", 2, "x", v, ok)
+	}
+
+	if _, ok := tree.Find("b"); ok {
+		t.Errorf("Element %v should not have been found", "b")
+	}
+
+	if !tree.Delete("m") || tree.Len() != 4 {
+		t.Errorf("Element %v should have been removed", "m")
+	}
+
+	expected := []int{3, 1, 4, 2}
+	i := 0
+	for v := range tree.Traverse(InOrder) {
+		if v != expected[i] {
+			t.Errorf("Expected to traverse %v, but instead traversed %v", expected[i], v)
+		}
+		i++
+	}
+}
+
+func TestTree_Comparator(t *testing.T) {
+	type version struct{ major, minor int }
+
+	tree := NewFunc[version, string](func(a, b version) int {
+		if c := a.major - b.major; c != 0 {
+			return c
+		}
+		return a.minor - b.minor
+	})
+
+	tree.Insert(version{1, 2}, "1.2")
+	tree.Insert(version{0, 9}, "0.9")
+	tree.Insert(version{1, 10}, "1.10")
+
+	if tree.Insert(version{1, 2}, "dup") {
+		t.Error("Duplicate elements should not be added")
+	}
+
+	expected := []string{"0.9", "1.2", "1.10"}
+	i := 0
+	for v := range tree.Traverse(InOrder) {
+		if v != expected[i] {
+			t.Errorf("Expected to traverse %v, but instead traversed %v", expected[i], v)
+		}
+		i++
+	}
+}
+
+func TestRankSelect(t *testing.T) {
+	bst := new(T)
+	m := make(map[int]bool)
+
+	for i := 0; i < 2000; i++ {
+		k := rand.Intn(200)
+		if rand.Intn(3) == 0 {
+			bst.Delete(k)
+			delete(m, k)
+		} else {
+			bst.Insert(k, k)
+			m[k] = true
+		}
+	}
+	testSizes(t, &bst.Tree)
+
+	var keys []int
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for i, k := range keys {
+		if r := bst.Rank(k); r != i {
+			t.Errorf("Rank(%v) expected to be %v, but was %v", k, i, r)
+		}
+
+		if s, v, ok := bst.Select(i); !ok || s != k || v != k {
+			t.Errorf("Select(%v) expected to be %v, but was %v=%v (%t)", i, k, s, v, ok)
+		}
+	}
+
+	for _, i := range []int{-1, len(keys)} {
+		if _, _, ok := bst.Select(i); ok {
+			t.Errorf("Select(%v) should not have found anything", i)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		lo, hi := rand.Intn(220)-10, rand.Intn(220)-10
+
+		expected := 0
+		for _, k := range keys {
+			if lo <= k && k < hi {
+				expected++
+			}
+		}
+
+		if c := bst.CountRange(lo, hi); c != expected {
+			t.Errorf("CountRange(%v, %v) expected to be %v, but was %v", lo, hi, expected, c)
+		}
+	}
+}
+
+func TestRank_Empty(t *testing.T) {
+	bst := new(T)
+
+	if r := bst.Rank(5); r != 0 {
+		t.Errorf("Rank of an empty tree expected to be 0, but was %v", r)
+	}
+
+	if c := bst.CountRange(0, 10); c != 0 {
+		t.Errorf("Count of an empty tree expected to be 0, but was %v", c)
+	}
+}
+
+// testSizes fails the test if the size of any node is wrong.
+func testSizes[K, V any](t *testing.T, tree *Tree[K, V]) {
+	t.Helper()
+
+	var check func(n *node[K, V]) int
+	check = func(n *node[K, V]) int {
+		if n == nil {
+			return 0
+		}
+
+		s := check(n.l) + check(n.r) + 1
+		if s != n.size {
+			t.Fatalf("Node %v expected to have a size of %v, but has %v", n.key, s, n.size)
+		}
+
+		return s
+	}
+
+	if s := check(tree.root); s != tree.count {
+		t.Fatalf("Tree has %v nodes, but a count of %v", s, tree.count)
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	bst := new(T)
+
+	if _, _, ok := bst.Min(); ok {
+		t.Error("Empty tree should not have a minimum")
+	}
+
+	if _, _, ok := bst.Max(); ok {
+		t.Error("Empty tree should not have a maximum")
+	}
+
+	for _, i := range []int{50, 30, 70, 20, 40, 60, 80, 35, 45} {
+		bst.Insert(i, i*10)
+	}
+
+	if k, v, ok := bst.Min(); !ok || k != 20 || v != 200 {
+		t.Errorf("Expected minimum %v, but was %v=%v (%t)", 20, k, v, ok)
+	}
+
+	if k, v, ok := bst.Max(); !ok || k != 80 || v != 800 {
+		t.Errorf("Expected maximum %v, but was %v=%v (%t)", 80, k, v, ok)
+	}
+
+	type query func(int) (int, interface{}, bool)
+	tests := []struct {
+		name     string
+		q        query
+		k        int
+		expected int
+		found    bool
+	}{
+		{"Floor", bst.Floor, 45, 45, true},
+		{"Floor", bst.Floor, 44, 40, true},
+		{"Floor", bst.Floor, 100, 80, true},
+		{"Floor", bst.Floor, 19, 0, false},
+		{"Lower", bst.Lower, 45, 40, true},
+		{"Lower", bst.Lower, 36, 35, true},
+		{"Lower", bst.Lower, 20, 0, false},
+		{"Ceiling", bst.Ceiling, 35, 35, true},
+		{"Ceiling", bst.Ceiling, 46, 50, true},
+		{"Ceiling", bst.Ceiling, 0, 20, true},
+		{"Ceiling", bst.Ceiling, 81, 0, false},
+		{"Higher", bst.Higher, 45, 50, true},
+		{"Higher", bst.Higher, 55, 60, true},
+		{"Higher", bst.Higher, 80, 0, false},
+		{"Predecessor", bst.Predecessor, 50, 45, true},
+		{"Predecessor", bst.Predecessor, 35, 30, true},
+		{"Predecessor", bst.Predecessor, 20, 0, false},
+		{"Predecessor", bst.Predecessor, 51, 0, false},
+		{"Successor", bst.Successor, 45, 50, true},
+		{"Successor", bst.Successor, 50, 60, true},
+		{"Successor", bst.Successor, 80, 0, false},
+		{"Successor", bst.Successor, 49, 0, false},
+	}
+
+	for _, tt := range tests {
+		k, v, ok := tt.q(tt.k)
+		if ok != tt.found || ok && (k != tt.expected || v != tt.expected*10) {
+			t.Errorf("%v(%v) expected %v (%t), but was %v=%v (%t)", tt.name, tt.k, tt.expected, tt.found, k, v, ok)
+		}
+	}
+}
+
+func TestNavigation_Random(t *testing.T) {
+	tree := New[int, int]()
+	var keys []int
+
+	for _, i := range rand.Perm(100) {
+		tree.Insert(i*2, i)
+		keys = append(keys, i*2)
+	}
+	slices.Sort(keys)
+
+	for k := -1; k <= 200; k++ {
+		i, found := slices.BinarySearch(keys, k)
+
+		floor, lower, ceiling, higher := i-1, i-1, i, i
+		if found {
+			floor, higher = i, i+1
+		}
+
+		tests := []struct {
+			name string
+			i    int
+			q    func(int) (int, int, bool)
+		}{
+			{"Floor", floor, tree.Floor},
+			{"Lower", lower, tree.Lower},
+			{"Ceiling", ceiling, tree.Ceiling},
+			{"Higher", higher, tree.Higher},
+		}
+
+		for _, tt := range tests {
+			r, _, ok := tt.q(k)
+			if valid := tt.i >= 0 && tt.i < len(keys); ok != valid || ok && r != keys[tt.i] {
+				t.Errorf("%v(%v) was %v (%t)", tt.name, k, r, ok)
+			}
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	bst := new(T)
+	for _, i := range rand.Perm(50) {
+		bst.Insert(i*2, i*2)
+	}
+
+	tests := []struct {
+		lo, hi                   int
+		loInclusive, hiInclusive bool
+		expected                 []int
+	}{
+		{10, 16, true, true, []int{10, 12, 14, 16}},
+		{10, 16, false, true, []int{12, 14, 16}},
+		{10, 16, true, false, []int{10, 12, 14}},
+		{10, 16, false, false, []int{12, 14}},
+		{9, 15, true, true, []int{10, 12, 14}},
+		{-5, 3, true, true, []int{0, 2}},
+		{95, 200, false, false, []int{96, 98}},
+		{40, 40, true, true, []int{40}},
+		{40, 40, true, false, nil},
+		{41, 41, true, true, nil},
+		{20, 10, true, true, nil},
+	}
+
+	for _, tt := range tests {
+		var keys []int
+		for k, v := range bst.Range(tt.lo, tt.hi, tt.loInclusive, tt.hiInclusive) {
+			if k != v {
+				t.Errorf("Range value for %v was %v", k, v)
+			}
+			keys = append(keys, k)
+		}
+
+		if !slices.Equal(keys, tt.expected) {
+			t.Errorf("Range(%v, %v, %t, %t) expected %v, but was %v", tt.lo, tt.hi, tt.loInclusive, tt.hiInclusive, tt.expected, keys)
+		}
+
+		keys = keys[:0]
+		for k := range bst.RangeDesc(tt.lo, tt.hi, tt.loInclusive, tt.hiInclusive) {
+			keys = append(keys, k)
+		}
+		slices.Reverse(keys)
+
+		if !slices.Equal(keys, tt.expected) {
+			t.Errorf("RangeDesc(%v, %v, %t, %t) expected %v reversed, but was %v", tt.lo, tt.hi, tt.loInclusive, tt.hiInclusive, tt.expected, keys)
+		}
+	}
+
+	n := 0
+	for range bst.Range(0, 100, true, true) {
+		if n++; n == 3 {
+			break
+		}
+	}
+
+	if n != 3 {
+		t.Errorf("Expected to stop after %v elements, but scanned %v", 3, n)
+	}
+}
+
+func TestRange_Prunes(t *testing.T) {
+	visited := 0
+	tree := NewFunc[int, int](func(a, b int) int {
+		visited++
+		return a - b
+	})
+
+	// a degenerate tree, where a range at the start must not scan the rest
+	for i := 0; i < 1000; i++ {
+		tree.Insert(i, i)
+	}
+
+	visited = 0
+	for range tree.Range(0, 2, true, true) {
+	}
+
+	if visited > 10 {
+		t.Errorf("Range should have pruned the tree, but made %v comparisons", visited)
+	}
+}
+
+func TestDeleteRange(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		bst := new(T)
+		m := make(map[int]bool)
+		for _, k := range rand.Perm(100) {
+			bst.Insert(k, k)
+			m[k] = true
+		}
+
+		lo, hi := rand.Intn(120)-10, rand.Intn(120)-10
+
+		expected := 0
+		for k := range m {
+			if lo <= k && k < hi {
+				expected++
+				delete(m, k)
+			}
+		}
+
+		if c := bst.DeleteRange(lo, hi); c != expected {
+			t.Errorf("DeleteRange(%v, %v) expected to remove %v keys, but removed %v", lo, hi, expected, c)
+		}
+
+		if c := bst.Len(); c != len(m) {
+			t.Errorf("Tree expected to have %v elements, but has %v", len(m), c)
+		}
+		testSizes(t, &bst.Tree)
+
+		keys := slices.Collect(bst.Keys())
+		if !slices.IsSorted(keys) {
+			t.Errorf("Keys expected to be in order, but were %v", keys)
+		}
+
+		for _, k := range keys {
+			if !m[k] {
+				t.Errorf("Element %v should have been removed", k)
+			}
+		}
+	}
+}
+
+func TestSplitJoin(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		bst := new(T)
+		for _, k := range rand.Perm(rand.Intn(50)) {
+			bst.Insert(k*2, k)
+		}
+		keys := slices.Collect(bst.Keys())
+
+		k := rand.Intn(110) - 5
+		left, right := bst.Split(k)
+
+		if bst.Len() != 0 || bst.root != nil {
+			t.Errorf("Tree expected to be empty after Split, but has %v elements", bst.Len())
+		}
+
+		l, r := slices.Collect(left.Keys()), slices.Collect(right.Keys())
+		if !slices.Equal(append(slices.Clone(l), r...), keys) {
+			t.Errorf("Split(%v) of %v expected to keep the keys in order, but was %v and %v", k, keys, l, r)
+		}
+
+		if len(l) > 0 && l[len(l)-1] >= k || len(r) > 0 && r[0] < k {
+			t.Errorf("Split(%v) put keys on the wrong side: %v and %v", k, l, r)
+		}
+
+		if left.Len() != len(l) || right.Len() != len(r) {
+			t.Errorf("Split(%v) expected counts %v and %v, but were %v and %v", k, len(l), len(r), left.Len(), right.Len())
+		}
+		testSizes(t, &left.Tree)
+		testSizes(t, &right.Tree)
+
+		joined, ok := Join(left, right)
+		if !ok {
+			t.Fatalf("Join of %v and %v should have succeeded", l, r)
+		}
+
+		if j := slices.Collect(joined.Keys()); !slices.Equal(j, keys) {
+			t.Errorf("Join expected to restore %v, but was %v", keys, j)
+		}
+
+		if joined.Len() != len(keys) || left.Len() != 0 || right.Len() != 0 {
+			t.Errorf("Join expected counts %v, 0 and 0, but were %v, %v and %v", len(keys), joined.Len(), left.Len(), right.Len())
+		}
+		testSizes(t, &joined.Tree)
+
+		// the joined tree must keep working as a regular tree
+		joined.Insert(k, k)
+		if len(keys) > 0 {
+			joined.Delete(keys[0])
+		}
+		testSizes(t, &joined.Tree)
+	}
+}
+
+func TestJoin_Overlapping(t *testing.T) {
+	a, b := New[int, int](), New[int, int]()
+	for _, k := range []int{1, 5, 9} {
+		a.Insert(k, k)
+	}
+	for _, k := range []int{9, 12} {
+		b.Insert(k, k)
+	}
+
+	if a.Join(b) {
+		t.Error("Joining trees with overlapping keys should fail")
+	}
+
+	if a.Len() != 3 || b.Len() != 2 {
+		t.Errorf("Trees should be unchanged after a failed join, but have %v and %v elements", a.Len(), b.Len())
+	}
+
+	if !a.Join(New[int, int]()) || !a.Join(nil) || a.Len() != 3 {
+		t.Error("Joining an empty tree should not change anything")
+	}
+
+	empty := New[int, int]()
+	if !empty.Join(b) || empty.Len() != 2 || b.Len() != 0 {
+		t.Error("Joining into an empty tree should move all the keys")
+	}
+
+	x, y := new(T), new(T)
+	x.Insert(1, nil)
+	y.Insert(1, nil)
+	if j, ok := Join(x, y); ok || j != nil || x.Len() != 1 || y.Len() != 1 {
+		t.Errorf("Joining trees with overlapping keys should fail and keep them, but got %v (%t)", j, ok)
+	}
+
+	if j, ok := Join(nil, y); !ok || j.Len() != 1 || y.Len() != 0 {
+		t.Error("Joining with a nil tree should move all the keys")
+	}
+}
+
+func TestDegenerate(t *testing.T) {
+	// a recursive implementation would need a frame per level of
+	// these trees, which is far more than the stack is allowed to grow
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 17))
+
+	const n = 1 << 13
+
+	descending := sorted(n)
+	slices.Reverse(descending)
+
+	for _, keys := range [][]int{sorted(n), descending} {
+		tree := New[int, int]()
+		for _, k := range keys {
+			if !tree.Insert(k, k) {
+				t.Fatalf("Element %v should have been added to the tree", k)
+			}
+		}
+
+		if tree.Insert(keys[len(keys)-1], 0) || tree.Delete(n) {
+			t.Error("The tree should not change when inserting or deleting at the bottom")
+		}
+
+		if v, ok := tree.Find(keys[len(keys)-1]); !ok || v != keys[len(keys)-1] {
+			t.Errorf("Element %v was not found", keys[len(keys)-1])
+		}
+
+		for _, tt := range []TraversalType{InOrder, PreOrder, PostOrder} {
+			c := 0
+			for range tree.Walk(tt) {
+				c++
+			}
+
+			if c != n {
+				t.Errorf("Traversal %v expected %v elements, but traversed %v", tt, n, c)
+			}
+		}
+
+		if k, _, ok := tree.Select(n - 1); !ok || k != n-1 || tree.Rank(n) != n {
+			t.Errorf("Select(%v) expected %v, but was %v (%t)", n-1, n-1, k, ok)
+		}
+
+		if h := tree.Height(); h != n {
+			t.Errorf("Tree expected to have a height of %v, but was %v", n, h)
+		}
+
+		if k, _, ok := tree.Floor(n); !ok || k != n-1 {
+			t.Errorf("Floor(%v) expected %v, but was %v (%t)", n, n-1, k, ok)
+		}
+
+		c := 0
+		for range tree.RangeDesc(0, n, true, false) {
+			c++
+		}
+
+		if c != n {
+			t.Errorf("RangeDesc expected %v elements, but traversed %v", n, c)
+		}
+
+		left, right := tree.Split(n / 2)
+		if !left.Join(right) || left.Len() != n {
+			t.Errorf("Split and Join expected to keep %v elements, but kept %v", n, left.Len())
+		}
+
+		if r := left.DeleteRange(n/4, n/2); r != n/4 {
+			t.Errorf("DeleteRange expected to remove %v elements, but removed %v", n/4, r)
+		}
+
+		for _, k := range keys {
+			left.Delete(k)
+		}
+
+		if left.Len() != 0 || left.root != nil {
+			t.Errorf("Tree expected to be empty, but has %v elements", left.Len())
+		}
+	}
+}
+
+// sorted returns the keys from 0 to n-1 in ascending order.
+func sorted(n int) []int {
+	keys := make([]int, n)
+	for i := range keys {
+		keys[i] = i
+	}
+
+	return keys
+}
+
+func (t *Tree[K, V]) String() (s string) {
 	print(t.root, &s)
 	return
 }
 
-func print(n *node, s *string) {
+func print[K, V any](n *node[K, V], s *string) {
 	if n == nil {
 		return
 	}
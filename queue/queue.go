@@ -3,6 +3,111 @@ package queue
 
 import "container/list"
 
+// Queue is the internal representation of a queue of elements of type T.
+// Elements are kept in a circular buffer that grows as needed.
+// The zero value is an empty queue ready to use.
+type Queue[T any] struct {
+	buf  []T
+	head int
+	n    int
+}
+
+// Init initializes the queue with capacity for at least size elements.
+// Calling Init is optional, but avoids resizing when the size is known.
+// O(1)
+func (q *Queue[T]) Init(size int) {
+	q.buf = make([]T, capacity(size))
+	q.head = 0
+	q.n = 0
+}
+
+// Push enqueues an element to the back of the queue.
+// Amortized: O(1)
+func (q *Queue[T]) Push(v T) {
+	if q.n == len(q.buf) {
+		q.grow()
+	}
+
+	q.buf[(q.head+q.n)&(len(q.buf)-1)] = v
+	q.n++
+}
+
+// Pop dequeues the element at the front of the queue.
+// The boolean is false if the queue was empty.
+// O(1)
+func (q *Queue[T]) Pop() (v T, ok bool) {
+	if q.n == 0 {
+		return v, false
+	}
+
+	v = q.buf[q.head]
+	// clear the slot so the element can be garbage collected
+	var zero T
+	q.buf[q.head] = zero
+	q.head = (q.head + 1) & (len(q.buf) - 1)
+	q.n--
+
+	return v, true
+}
+
+// Peek returns the element at the front of the queue without removing it.
+// The boolean is false if the queue is empty.
+// O(1)
+func (q *Queue[T]) Peek() (v T, ok bool) {
+	if q.n == 0 {
+		return v, false
+	}
+
+	return q.buf[q.head], true
+}
+
+// PeekBack returns the element at the back of the queue without removing it.
+// The boolean is false if the queue is empty.
+// O(1)
+func (q *Queue[T]) PeekBack() (v T, ok bool) {
+	if q.n == 0 {
+		return v, false
+	}
+
+	return q.buf[(q.head+q.n-1)&(len(q.buf)-1)], true
+}
+
+// Len returns the number of elements in the queue.
+// O(1)
+func (q *Queue[T]) Len() int {
+	return q.n
+}
+
+// IsEmpty returns true if the queue has no elements.
+// O(1)
+func (q *Queue[T]) IsEmpty() bool {
+	return q.n == 0
+}
+
+// grow doubles the size of the buffer, unwrapping the elements
+// so that the front of the queue is at index 0.
+func (q *Queue[T]) grow() {
+	buf := make([]T, capacity(2*len(q.buf)))
+	if q.n > 0 {
+		c := copy(buf, q.buf[q.head:])
+		copy(buf[c:], q.buf[:q.head])
+	}
+
+	q.buf = buf
+	q.head = 0
+}
+
+// capacity returns the smallest power of two that can hold size elements.
+// The buffer is always a power of two so indexes wrap with a mask.
+func capacity(size int) int {
+	c := 1
+	for c < size {
+		c <<= 1
+	}
+
+	return c
+}
+
 // Q is the internal representation of the data structure.
 type Q struct {
 	l *list.List
@@ -50,6 +50,78 @@ func TestIsEmpty(t *testing.T) {
 	}
 }
 
+func TestQueuePushPop(t *testing.T) {
+	var q Queue[int]
+
+	for i := 0; i < iterations; i++ {
+		q.Push(i)
+	}
+
+	for i := 0; i < iterations; i++ {
+		if v, ok := q.Pop(); !ok || v != i {
+			t.Errorf("Popping expected %v, got %v (%t)", i, v, ok)
+		}
+	}
+
+	if v, ok := q.Pop(); ok {
+		t.Errorf("Popping an empty queue should fail, got %v", v)
+	}
+}
+
+func TestQueueWrapAround(t *testing.T) {
+	q := new(Queue[int])
+	q.Init(4)
+
+	// interleave pushes and pops so the head moves around the buffer
+	// before it needs to grow
+	next := 0
+	for i := 0; i < iterations; i++ {
+		q.Push(i)
+		if i%3 == 0 {
+			if v, _ := q.Pop(); v != next {
+				t.Errorf("Popping expected %v, got %v", next, v)
+			}
+			next++
+		}
+	}
+
+	if l := q.Len(); l != iterations-next {
+		t.Errorf("Queue length was expected to be %v, but is %v", iterations-next, l)
+	}
+
+	for !q.IsEmpty() {
+		if v, _ := q.Pop(); v != next {
+			t.Errorf("Popping expected %v, got %v", next, v)
+		}
+		next++
+	}
+}
+
+func TestQueuePeek(t *testing.T) {
+	var q Queue[string]
+
+	if _, ok := q.Peek(); ok {
+		t.Error("Peeking an empty queue should fail")
+	}
+
+	if _, ok := q.PeekBack(); ok {
+		t.Error("Peeking the back of an empty queue should fail")
+	}
+
+	q.Push("a")
+	q.Push("b")
+	q.Push("c")
+	q.Pop()
+
+	if v, ok := q.Peek(); !ok || v != "b" {
+		t.Errorf("Peeking expected %v, got %v (%t)", "b", v, ok)
+	}
+
+	if v, ok := q.PeekBack(); !ok || v != "c" {
+		t.Errorf("Peeking the back expected %v, got %v (%t)", "c", v, ok)
+	}
+}
+
 func testPop(t *testing.T, q *Q, e interface{}) {
 	if v := q.Pop(); v != e {
 		t.Errorf("Popping expected %v, got %v", e, v)
@@ -79,3 +151,43 @@ func BenchmarkPop(b *testing.B) {
 		q.Pop()
 	}
 }
+
+func BenchmarkPushPop(b *testing.B) {
+	q := new(Q)
+	q.Init()
+
+	for i := 0; i < b.N; i++ {
+		q.Push(i)
+		q.Pop()
+	}
+}
+
+func BenchmarkQueuePush(b *testing.B) {
+	var q Queue[int]
+
+	for i := 0; i < b.N; i++ {
+		q.Push(i)
+	}
+}
+
+func BenchmarkQueuePop(b *testing.B) {
+	var q Queue[int]
+
+	for i := 0; i < b.N; i++ {
+		q.Push(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Pop()
+	}
+}
+
+func BenchmarkQueuePushPop(b *testing.B) {
+	var q Queue[int]
+
+	for i := 0; i < b.N; i++ {
+		q.Push(i)
+		q.Pop()
+	}
+}
@@ -0,0 +1,293 @@
+// Package radix implements a radix tree.
+//
+// A radix tree is a trie where every node with a single child is merged
+// with that child, so edges are labeled with whole strings instead of
+// single characters. Keys are compared byte by byte.
+package radix
+
+import (
+	"iter"
+	"strings"
+)
+
+// T is the internal representation of a radix tree.
+// The zero value is an empty tree ready to use.
+type T struct {
+	root  node
+	words int
+}
+
+// node is the internal representation of a radix tree node.
+type node struct {
+	// prefix is the label of the edge leading to the node
+	prefix string
+	// children are sorted by the first byte of their prefix,
+	// which is unique among siblings
+	children []*node
+	end      bool
+	value    interface{}
+}
+
+// Insert adds a new word to the tree.
+// The word may be accompanied by a value.
+// If the word is already in the tree, its value is left unchanged.
+// O(k) where k is the length of the word
+func (t *T) Insert(s string, v interface{}) {
+	n := &t.root
+
+	for len(s) > 0 {
+		i, c := n.child(s[0])
+		if c == nil {
+			// nothing shares the rest of the word, so it becomes a leaf
+			l := &node{prefix: s, end: true, value: v}
+			n.children = append(n.children, nil)
+			copy(n.children[i+1:], n.children[i:])
+			n.children[i] = l
+			t.words++
+			return
+		}
+
+		l := common(s, c.prefix)
+		if l < len(c.prefix) {
+			// the word diverges in the middle of the edge,
+			// so split it with a new intermediate node
+			m := &node{prefix: c.prefix[:l], children: []*node{c}}
+			c.prefix = c.prefix[l:]
+			n.children[i] = m
+			c = m
+		}
+
+		s = s[l:]
+		n = c
+	}
+
+	if !n.end {
+		n.end = true
+		n.value = v
+		t.words++
+	}
+}
+
+// Delete returns true if the given word was removed from the tree.
+// O(k) where k is the length of the word
+func (t *T) Delete(s string) bool {
+	// keep track of the last two edges followed,
+	// since removing a node may merge its parent
+	var p, gp *node
+	var pi, gpi int
+
+	n := &t.root
+	for len(s) > 0 {
+		i, c := n.child(s[0])
+		if c == nil || !strings.HasPrefix(s, c.prefix) {
+			return false
+		}
+
+		gp, gpi = p, pi
+		p, pi = n, i
+		s = s[len(c.prefix):]
+		n = c
+	}
+
+	if !n.end {
+		return false
+	}
+
+	n.end = false
+	n.value = nil
+	t.words--
+
+	switch {
+	case p == nil:
+		// the root is never removed or merged
+	case len(n.children) == 0:
+		p.children = append(p.children[:pi], p.children[pi+1:]...)
+		// the parent may now be a pass through node
+		if gp != nil && !p.end && len(p.children) == 1 {
+			gp.children[gpi] = p.merge()
+		}
+	case len(n.children) == 1:
+		p.children[pi] = n.merge()
+	}
+
+	return true
+}
+
+// merge returns the only child of a node, with the node's prefix prepended.
+func (n *node) merge() *node {
+	c := n.children[0]
+	c.prefix = n.prefix + c.prefix
+
+	return c
+}
+
+// Has returns true if the tree contains the given word.
+// O(k) where k is the length of the word
+func (t *T) Has(s string) bool {
+	_, r := t.Get(s)
+	return r
+}
+
+// Get returns the value stored with the string and
+// true if the tree contains the given word.
+// O(k) where k is the length of the word
+func (t *T) Get(s string) (interface{}, bool) {
+	n := &t.root
+	for len(s) > 0 {
+		_, c := n.child(s[0])
+		if c == nil || !strings.HasPrefix(s, c.prefix) {
+			return nil, false
+		}
+
+		s = s[len(c.prefix):]
+		n = c
+	}
+
+	if !n.end {
+		return nil, false
+	}
+
+	return n.value, true
+}
+
+// LongestPrefix returns the longest word in the tree that is a prefix
+// of the given string, its value, and true if there is such a word.
+// O(k) where k is the length of the string
+func (t *T) LongestPrefix(s string) (string, interface{}, bool) {
+	n := &t.root
+	l, found := 0, n
+	consumed := 0
+
+	for {
+		if n.end {
+			l, found = consumed, n
+		}
+
+		if consumed == len(s) {
+			break
+		}
+
+		_, c := n.child(s[consumed])
+		if c == nil || !strings.HasPrefix(s[consumed:], c.prefix) {
+			break
+		}
+
+		consumed += len(c.prefix)
+		n = c
+	}
+
+	if !found.end {
+		return "", nil, false
+	}
+
+	return s[:l], found.value, true
+}
+
+// StartsWith returns all words in the tree that begin with
+// the given string, in lexicographic order.
+// O(n)
+func (t *T) StartsWith(s string) (matches []string) {
+	for w := range t.PrefixSeq(s) {
+		matches = append(matches, w)
+	}
+
+	return
+}
+
+// All returns an iterator over all the words in the tree and
+// their values, in lexicographic order.
+// O(n)
+func (t *T) All() iter.Seq2[string, interface{}] {
+	return t.PrefixSeq("")
+}
+
+// PrefixSeq returns an iterator over the words in the tree that begin
+// with the given string and their values, in lexicographic order.
+// Words are produced lazily, so stopping early skips the rest of the tree.
+// O(n)
+func (t *T) PrefixSeq(s string) iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		n := &t.root
+		buf := make([]byte, 0, len(s)+16)
+
+		for len(s) > 0 {
+			_, c := n.child(s[0])
+			if c == nil {
+				return
+			}
+
+			if strings.HasPrefix(s, c.prefix) {
+				s = s[len(c.prefix):]
+			} else if strings.HasPrefix(c.prefix, s) {
+				// the prefix ends in the middle of the edge
+				s = ""
+			} else {
+				return
+			}
+
+			buf = append(buf, c.prefix...)
+			n = c
+		}
+
+		match(n, buf, yield)
+	}
+}
+
+// match recursively yields the words under a given node in lexicographic order.
+// It returns false once yield asks to stop.
+func match(n *node, buf []byte, yield func(string, interface{}) bool) bool {
+	if n.end && !yield(string(buf), n.value) {
+		return false
+	}
+
+	for _, c := range n.children {
+		if !match(c, append(buf, c.prefix...), yield) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Clear removes all the elements from the tree.
+// O(1)
+func (t *T) Clear() {
+	t.root = node{}
+	t.words = 0
+}
+
+// Len returns the number of words in the tree.
+// O(1)
+func (t *T) Len() int {
+	return t.words
+}
+
+// child returns the child whose prefix starts with b, or nil and the
+// position where such a child would be inserted.
+func (n *node) child(b byte) (int, *node) {
+	lo, hi := 0, len(n.children)
+	for lo < hi {
+		m := (lo + hi) / 2
+		if n.children[m].prefix[0] < b {
+			lo = m + 1
+		} else {
+			hi = m
+		}
+	}
+
+	if lo < len(n.children) && n.children[lo].prefix[0] == b {
+		return lo, n.children[lo]
+	}
+
+	return lo, nil
+}
+
+// common returns the length of the common prefix of two strings.
+func common(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	return i
+}
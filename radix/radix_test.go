@@ -0,0 +1,309 @@
+package radix
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/cosn/collections/trie"
+)
+
+func TestInsertHas(t *testing.T) {
+	values := []string{"hey", "hello", "hell", "magazine", "magnificent", "magazines"}
+	tree := new(T)
+
+	for _, s := range values {
+		tree.Insert(s, nil)
+	}
+
+	for _, s := range values {
+		if !tree.Has(s) {
+			t.Errorf("Tree expected to contain '%v', but did not", s)
+		}
+	}
+
+	for _, s := range []string{"mag", "he", "magazin", "hello!", ""} {
+		if tree.Has(s) {
+			t.Errorf("Unexpected value '%v' found in tree", s)
+		}
+	}
+}
+
+func TestInsertGet(t *testing.T) {
+	values := []string{"hey", "hello", "hell", "magazine", "magnificent", "magazines", ""}
+	tree := new(T)
+
+	for _, s := range values {
+		tree.Insert(s, []byte(s))
+	}
+
+	// existing words keep their values
+	tree.Insert("hell", nil)
+
+	for _, s := range values {
+		v, exists := tree.Get(s)
+
+		if !exists {
+			t.Errorf("Tree expected to contain '%v', but did not", s)
+		}
+
+		if s != string(v.([]byte)) {
+			t.Errorf("Tree value expected to be %q, but was %v", []byte(s), v)
+		}
+	}
+
+	if l := tree.Len(); l != len(values) {
+		t.Errorf("Tree length expected to be %v, but instead was %v", len(values), l)
+	}
+}
+
+func TestStartsWith(t *testing.T) {
+	tree := new(T)
+
+	for _, s := range []string{"foobar", "fob", "foo", "f", "bar", "fo", "foobaz"} {
+		tree.Insert(s, len(s))
+	}
+
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"", []string{"bar", "f", "fo", "fob", "foo", "foobar", "foobaz"}},
+		{"fo", []string{"fo", "fob", "foo", "foobar", "foobaz"}},
+		{"foob", []string{"foobar", "foobaz"}},
+		{"fooba", []string{"foobar", "foobaz"}},
+		{"foobar", []string{"foobar"}},
+		{"foobarr", nil},
+		{"fx", nil},
+		{"z", nil},
+	}
+
+	for _, tt := range tests {
+		if m := tree.StartsWith(tt.prefix); !slices.Equal(m, tt.expected) {
+			t.Errorf("StartsWith(%q) expected %v, but was %v", tt.prefix, tt.expected, m)
+		}
+	}
+
+	for w, v := range tree.All() {
+		if v != len(w) {
+			t.Errorf("Value for %q expected to be %v, but was %v", w, len(w), v)
+		}
+	}
+
+	n := 0
+	for range tree.PrefixSeq("f") {
+		if n++; n == 2 {
+			break
+		}
+	}
+
+	if n != 2 {
+		t.Errorf("Expected to stop after %v matches, but found %v", 2, n)
+	}
+}
+
+func TestLongestPrefix(t *testing.T) {
+	tree := new(T)
+
+	for _, s := range []string{"/", "/api", "/api/v1", "/api/v1/users", "/static"} {
+		tree.Insert(s, s)
+	}
+
+	tests := []struct {
+		s, expected string
+		found       bool
+	}{
+		{"/api/v1/users/42", "/api/v1/users", true},
+		{"/api/v1/user", "/api/v1", true},
+		{"/api/v2", "/api", true},
+		{"/index.html", "/", true},
+		{"/api", "/api", true},
+		{"api", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		k, v, ok := tree.LongestPrefix(tt.s)
+		if ok != tt.found || k != tt.expected || ok && v != tt.expected {
+			t.Errorf("LongestPrefix(%q) expected %q (%t), but was %q=%v (%t)", tt.s, tt.expected, tt.found, k, v, ok)
+		}
+	}
+
+	tree.Insert("", "root")
+	if k, v, ok := tree.LongestPrefix("api"); !ok || k != "" || v != "root" {
+		t.Errorf("LongestPrefix(%q) expected the empty word, but was %q=%v (%t)", "api", k, v, ok)
+	}
+}
+
+func TestLenClear(t *testing.T) {
+	tree := new(T)
+
+	for _, s := range []string{"hello", "hell", "hey", "heck", "blah", "boo", "foo", "foobar", "moo"} {
+		tree.Insert(s, nil)
+	}
+
+	if l := tree.Len(); l != 9 {
+		t.Errorf("Tree length expected to be 9, but instead was %v", l)
+	}
+
+	tree.Clear()
+	if l := tree.Len(); l != 0 {
+		t.Errorf("Tree expected to be empty, instead has %v elements", l)
+	}
+
+	if tree.Has("hello") {
+		t.Error("Tree should be empty after Clear")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	values := []string{"hey", "hello", "hell", "magazine", "magnificent", "magazines"}
+	expected := []string{"hey", "hello", "magazine", "magnificent"}
+
+	tree := new(T)
+
+	for _, s := range values {
+		tree.Insert(s, nil)
+	}
+
+	if !tree.Delete("magazines") {
+		t.Error("Value should have been removed")
+	}
+
+	if tree.Has("magazines") {
+		t.Error("Word should have been removed, but is still in tree")
+	}
+
+	if !tree.Delete("hell") {
+		t.Error("Value should have been removed")
+	}
+
+	if tree.Delete("hell") || tree.Delete("mag") || tree.Delete("xyz") {
+		t.Error("Missing words should not be removed")
+	}
+
+	if l := tree.Len(); l != len(expected) {
+		t.Errorf("Number of words should be %v, but instead was %v", len(expected), l)
+	}
+
+	for _, s := range expected {
+		if !tree.Has(s) {
+			t.Errorf("Tree expected to contain '%v', but did not", s)
+		}
+	}
+	testInvariants(t, tree)
+}
+
+func TestRandomOperations(t *testing.T) {
+	tree := new(T)
+	m := make(map[string]bool)
+
+	for i := 0; i < 5000; i++ {
+		// short words over a small alphabet share lots of prefixes
+		b := make([]byte, rand.Intn(6))
+		for j := range b {
+			b[j] = "abc"[rand.Intn(3)]
+		}
+		s := string(b)
+
+		if rand.Intn(3) == 0 {
+			if tree.Delete(s) != m[s] {
+				t.Fatalf("Delete(%q) disagrees with the expected contents", s)
+			}
+			delete(m, s)
+		} else {
+			tree.Insert(s, s)
+			m[s] = true
+		}
+		testInvariants(t, tree)
+	}
+
+	var expected []string
+	for s := range m {
+		expected = append(expected, s)
+	}
+	slices.Sort(expected)
+
+	if w := tree.StartsWith(""); !slices.Equal(w, expected) {
+		t.Errorf("Tree expected to contain %v, but contains %v", expected, w)
+	}
+}
+
+// testInvariants fails the test if the tree isn't fully compressed
+// or its children are out of order.
+func testInvariants(t *testing.T, tree *T) {
+	t.Helper()
+
+	var check func(n *node) error
+	check = func(n *node) error {
+		if n != &tree.root && !n.end && len(n.children) < 2 {
+			return fmt.Errorf("node %q should have been merged", n.prefix)
+		}
+
+		for i, c := range n.children {
+			if len(c.prefix) == 0 {
+				return fmt.Errorf("child of %q has an empty prefix", n.prefix)
+			}
+			if i > 0 && n.children[i-1].prefix[0] >= c.prefix[0] {
+				return fmt.Errorf("children of %q are out of order", n.prefix)
+			}
+			if err := check(c); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := check(&tree.root); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// words returns n random words sharing long prefixes, like paths.
+func words(n int) []string {
+	r := rand.New(rand.NewSource(1))
+	w := make([]string, n)
+	for i := range w {
+		w[i] = fmt.Sprintf("/api/v%d/resources/%d/items/%d", r.Intn(3), r.Intn(100), r.Intn(1000))
+	}
+
+	return w
+}
+
+func BenchmarkInsert(b *testing.B) {
+	w := words(b.N)
+	tree := new(T)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, s := range w {
+		tree.Insert(s, nil)
+	}
+}
+
+func BenchmarkInsertTrie(b *testing.B) {
+	w := words(b.N)
+	tree := new(trie.T)
+	tree.Init(256)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, s := range w {
+		tree.Insert(s, nil)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	w := words(1 << 14)
+	tree := new(T)
+	for _, s := range w {
+		tree.Insert(s, nil)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Get(w[i%len(w)])
+	}
+}
@@ -0,0 +1,394 @@
+// Package rbtree implements a left-leaning red-black tree.
+package rbtree
+
+import (
+	"cmp"
+	"iter"
+)
+
+// Tree is the internal representation of a red-black tree.
+// Trees must be created with New or NewFunc.
+type Tree[K, V any] struct {
+	root  *node[K, V]
+	count int
+	cmp   func(a, b K) int
+}
+
+// node is the internal representation of a red-black tree node.
+// The color of a node is the color of the link from its parent.
+type node[K, V any] struct {
+	key  K
+	val  V
+	l, r *node[K, V]
+	red  bool
+}
+
+// TraversalType represents one of the three known traversals.
+type TraversalType int
+
+const (
+	InOrder TraversalType = iota
+	PreOrder
+	PostOrder
+)
+
+// New returns an empty tree ordered by the natural ordering of its keys.
+// O(1)
+func New[K cmp.Ordered, V any]() *Tree[K, V] {
+	return NewFunc[K, V](cmp.Compare[K])
+}
+
+// NewFunc returns an empty tree ordered by the given comparator, which must
+// return a negative number when a < b, a positive number when a > b and
+// zero when a == b.
+// O(1)
+func NewFunc[K, V any](cmp func(a, b K) int) *Tree[K, V] {
+	return &Tree[K, V]{cmp: cmp}
+}
+
+// Insert adds a given key+value to the tree and returns true if it was added.
+// O(log(n))
+func (t *Tree[K, V]) Insert(k K, v V) (added bool) {
+	t.root, added = t.insert(t.root, k, v)
+	t.root.red = false
+	if added {
+		t.count++
+	}
+
+	return
+}
+
+// insert recursively adds a key+value in the tree,
+// rebalancing on the way back up.
+func (t *Tree[K, V]) insert(n *node[K, V], k K, v V) (*node[K, V], bool) {
+	if n == nil {
+		return &node[K, V]{key: k, val: v, red: true}, true
+	}
+
+	var added bool
+	if c := t.cmp(k, n.key); c < 0 {
+		n.l, added = t.insert(n.l, k, v)
+	} else if c > 0 {
+		n.r, added = t.insert(n.r, k, v)
+	} else {
+		return n, false
+	}
+
+	return balance(n), added
+}
+
+// Delete removes a given key from the tree and returns true if it was removed.
+// O(log(n))
+func (t *Tree[K, V]) Delete(k K) bool {
+	if _, found := t.Find(k); !found {
+		return false
+	}
+
+	// if both children are black, make the root red
+	// so there is a red link to push down the tree
+	if !isRed(t.root.l) && !isRed(t.root.r) {
+		t.root.red = true
+	}
+
+	t.root = t.delete(t.root, k)
+	if t.root != nil {
+		t.root.red = false
+	}
+	t.count--
+
+	return true
+}
+
+// delete recursively deletes a key known to be in the tree, keeping
+// the current node or one of its children red on the way down.
+func (t *Tree[K, V]) delete(n *node[K, V], k K) *node[K, V] {
+	if t.cmp(k, n.key) < 0 {
+		if !isRed(n.l) && !isRed(n.l.l) {
+			n = moveRedLeft(n)
+		}
+		n.l = t.delete(n.l, k)
+	} else {
+		if isRed(n.l) {
+			n = rotateRight(n)
+		}
+
+		if t.cmp(k, n.key) == 0 && n.r == nil {
+			return nil
+		}
+
+		if !isRed(n.r) && !isRed(n.r.l) {
+			n = moveRedRight(n)
+		}
+
+		if t.cmp(k, n.key) == 0 {
+			// replace the node with its successor
+			s := n.r
+			for s.l != nil {
+				s = s.l
+			}
+			n.key = s.key
+			n.val = s.val
+			n.r = deleteMin(n.r)
+		} else {
+			n.r = t.delete(n.r, k)
+		}
+	}
+
+	return balance(n)
+}
+
+// deleteMin recursively removes the smallest node under n.
+func deleteMin[K, V any](n *node[K, V]) *node[K, V] {
+	if n.l == nil {
+		return nil
+	}
+
+	if !isRed(n.l) && !isRed(n.l.l) {
+		n = moveRedLeft(n)
+	}
+	n.l = deleteMin(n.l)
+
+	return balance(n)
+}
+
+// Find returns the value found at the given key and
+// true if the tree contains the key.
+// O(log(n))
+func (t *Tree[K, V]) Find(k K) (v V, found bool) {
+	n := t.root
+	for n != nil {
+		if c := t.cmp(k, n.key); c < 0 {
+			n = n.l
+		} else if c > 0 {
+			n = n.r
+		} else {
+			return n.val, true
+		}
+	}
+
+	return v, false
+}
+
+// Min returns the smallest key in the tree and its value.
+// The boolean is false if the tree is empty.
+// O(log(n))
+func (t *Tree[K, V]) Min() (k K, v V, ok bool) {
+	n := t.root
+	if n == nil {
+		return k, v, false
+	}
+
+	for n.l != nil {
+		n = n.l
+	}
+
+	return n.key, n.val, true
+}
+
+// Max returns the largest key in the tree and its value.
+// The boolean is false if the tree is empty.
+// O(log(n))
+func (t *Tree[K, V]) Max() (k K, v V, ok bool) {
+	n := t.root
+	if n == nil {
+		return k, v, false
+	}
+
+	for n.r != nil {
+		n = n.r
+	}
+
+	return n.key, n.val, true
+}
+
+// Floor returns the largest key in the tree less than or equal to k and its value.
+// The boolean is false if there is no such key.
+// O(log(n))
+func (t *Tree[K, V]) Floor(k K) (K, V, bool) {
+	var f *node[K, V]
+	for n := t.root; n != nil; {
+		if c := t.cmp(k, n.key); c < 0 {
+			n = n.l
+		} else if c > 0 {
+			f = n
+			n = n.r
+		} else {
+			f = n
+			break
+		}
+	}
+
+	return result(f)
+}
+
+// Ceiling returns the smallest key in the tree greater than or equal to k and its value.
+// The boolean is false if there is no such key.
+// O(log(n))
+func (t *Tree[K, V]) Ceiling(k K) (K, V, bool) {
+	var f *node[K, V]
+	for n := t.root; n != nil; {
+		if c := t.cmp(k, n.key); c > 0 {
+			n = n.r
+		} else if c < 0 {
+			f = n
+			n = n.l
+		} else {
+			f = n
+			break
+		}
+	}
+
+	return result(f)
+}
+
+// result unpacks a node found by a query.
+func result[K, V any](n *node[K, V]) (k K, v V, ok bool) {
+	if n == nil {
+		return k, v, false
+	}
+
+	return n.key, n.val, true
+}
+
+// Len returns the number of elements in the tree.
+// O(1)
+func (t *Tree[K, V]) Len() int {
+	return t.count
+}
+
+// Clear removes all the nodes from the tree.
+// O(1)
+func (t *Tree[K, V]) Clear() {
+	t.root = nil
+	t.count = 0
+}
+
+// Traverse returns a buffered channel holding the values of the tree in
+// the order of the given traversal. The whole tree is walked up front and
+// the channel closed, so a reader may stop early without leaking anything.
+// O(n)
+func (t *Tree[K, V]) Traverse(tt TraversalType) <-chan V {
+	c := make(chan V, t.count)
+	for _, v := range t.Walk(tt) {
+		c <- v
+	}
+	close(c)
+
+	return c
+}
+
+// Walk returns an iterator over the key+value pairs of the tree
+// in the order given by the traversal type.
+// O(n)
+func (t *Tree[K, V]) Walk(tt TraversalType) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		switch tt {
+		case InOrder:
+			inOrder(t.root, yield)
+		case PreOrder:
+			preOrder(t.root, yield)
+		case PostOrder:
+			postOrder(t.root, yield)
+		}
+	}
+}
+
+// All returns an iterator over the key+value pairs of the tree
+// in ascending key order.
+// O(n)
+func (t *Tree[K, V]) All() iter.Seq2[K, V] {
+	return t.Walk(InOrder)
+}
+
+// inOrder yields the left, parent, right nodes.
+// It returns false once yield asks to stop.
+func inOrder[K, V any](n *node[K, V], yield func(K, V) bool) bool {
+	return n == nil || inOrder(n.l, yield) && yield(n.key, n.val) && inOrder(n.r, yield)
+}
+
+// preOrder yields the parent, left, right nodes.
+func preOrder[K, V any](n *node[K, V], yield func(K, V) bool) bool {
+	return n == nil || yield(n.key, n.val) && preOrder(n.l, yield) && preOrder(n.r, yield)
+}
+
+// postOrder yields the left, right, parent nodes.
+func postOrder[K, V any](n *node[K, V], yield func(K, V) bool) bool {
+	return n == nil || postOrder(n.l, yield) && postOrder(n.r, yield) && yield(n.key, n.val)
+}
+
+// isRed returns true if the link to the node is red.
+// Nil links are black.
+func isRed[K, V any](n *node[K, V]) bool {
+	return n != nil && n.red
+}
+
+// rotateLeft turns a right leaning red link into a left leaning one.
+func rotateLeft[K, V any](n *node[K, V]) *node[K, V] {
+	x := n.r
+	n.r = x.l
+	x.l = n
+	x.red = n.red
+	n.red = true
+
+	return x
+}
+
+// rotateRight turns a left leaning red link into a right leaning one.
+func rotateRight[K, V any](n *node[K, V]) *node[K, V] {
+	x := n.l
+	n.l = x.r
+	x.r = n
+	x.red = n.red
+	n.red = true
+
+	return x
+}
+
+// flip flips the colors of a node and its two children.
+func flip[K, V any](n *node[K, V]) {
+	n.red = !n.red
+	n.l.red = !n.l.red
+	n.r.red = !n.r.red
+}
+
+// moveRedLeft makes n.l or one of its children red,
+// assuming n is red and both n.l and n.l.l are black.
+func moveRedLeft[K, V any](n *node[K, V]) *node[K, V] {
+	flip(n)
+	if isRed(n.r.l) {
+		n.r = rotateRight(n.r)
+		n = rotateLeft(n)
+		flip(n)
+	}
+
+	return n
+}
+
+// moveRedRight makes n.r or one of its children red,
+// assuming n is red and both n.r and n.r.l are black.
+func moveRedRight[K, V any](n *node[K, V]) *node[K, V] {
+	flip(n)
+	if isRed(n.l.l) {
+		n = rotateRight(n)
+		flip(n)
+	}
+
+	return n
+}
+
+// balance restores the left-leaning invariants at a node.
+func balance[K, V any](n *node[K, V]) *node[K, V] {
+	if isRed(n.r) && !isRed(n.l) {
+		n = rotateLeft(n)
+	}
+
+	if isRed(n.l) && isRed(n.l.l) {
+		n = rotateRight(n)
+	}
+
+	if isRed(n.l) && isRed(n.r) {
+		flip(n)
+	}
+
+	return n
+}
@@ -0,0 +1,335 @@
+package rbtree
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestInsert(t *testing.T) {
+	expected := []int{5, 3, 7, 4, 6}
+	tree := New[int, int]()
+
+	for _, i := range expected {
+		if !tree.Insert(i, i) {
+			t.Errorf("Element %v should have been added to the tree", i)
+		}
+		testInvariants(t, tree)
+	}
+
+	for _, i := range expected {
+		if _, ok := tree.Find(i); !ok {
+			t.Errorf("Element %v expected to be in the tree, but was not", i)
+		}
+	}
+
+	if tree.Insert(4, 44) {
+		t.Error("Duplicate elements should not be added")
+	}
+
+	if v, _ := tree.Find(4); v == 44 {
+		t.Error("Previously inserted elements should not be updated")
+	}
+
+	if c := tree.Len(); c != len(expected) {
+		t.Errorf("Tree expected to have %v elements, but has %v instead", len(expected), c)
+	}
+}
+
+func TestInsert_Sorted(t *testing.T) {
+	const n = 1 << 12
+	tree := New[int, int]()
+
+	for i := 0; i < n; i++ {
+		tree.Insert(i, i)
+	}
+	testInvariants(t, tree)
+
+	// a left-leaning red-black tree is at most 2*log(n) high
+	if h := height(tree.root); h > 2*12 {
+		t.Errorf("Tree of %v sorted elements expected to be balanced, but has height %v", n, h)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tree := New[int, int]()
+	keys := rand.Perm(500)
+
+	for _, i := range keys {
+		tree.Insert(i, i)
+	}
+
+	for j, i := range rand.Perm(500) {
+		if !tree.Delete(i) {
+			t.Fatalf("Element %v should have been removed", i)
+		}
+
+		if _, ok := tree.Find(i); ok {
+			t.Fatalf("Element %v should not have been found", i)
+		}
+
+		if tree.Delete(i) {
+			t.Fatalf("Element %v should not be deleted twice", i)
+		}
+
+		if c := tree.Len(); c != len(keys)-j-1 {
+			t.Fatalf("Tree expected to have %v elements, but has %v instead", len(keys)-j-1, c)
+		}
+		testInvariants(t, tree)
+	}
+
+	if tree.root != nil {
+		t.Error("Tree should be empty")
+	}
+}
+
+func TestRandomOperations(t *testing.T) {
+	tree := New[int, int]()
+	m := make(map[int]bool)
+
+	for i := 0; i < 5000; i++ {
+		k := rand.Intn(200)
+		if rand.Intn(3) == 0 {
+			if tree.Delete(k) != m[k] {
+				t.Fatalf("Delete(%v) disagrees with the expected contents", k)
+			}
+			delete(m, k)
+		} else {
+			if tree.Insert(k, k) == m[k] {
+				t.Fatalf("Insert(%v) disagrees with the expected contents", k)
+			}
+			m[k] = true
+		}
+	}
+
+	if tree.Len() != len(m) {
+		t.Errorf("Tree expected to have %v elements, but has %v instead", len(m), tree.Len())
+	}
+	testInvariants(t, tree)
+}
+
+func TestMinMaxFloorCeiling(t *testing.T) {
+	tree := New[int, string]()
+
+	if _, _, ok := tree.Min(); ok {
+		t.Error("Min of an empty tree should fail")
+	}
+
+	if _, _, ok := tree.Max(); ok {
+		t.Error("Max of an empty tree should fail")
+	}
+
+	for _, i := range []int{10, 20, 30, 40, 50} {
+		tree.Insert(i, fmt.Sprint(i))
+	}
+
+	if k, v, _ := tree.Min(); k != 10 || v != "10" {
+		t.Errorf("Min expected to be %v, but was %v=%v", 10, k, v)
+	}
+
+	if k, v, _ := tree.Max(); k != 50 || v != "50" {
+		t.Errorf("Max expected to be %v, but was %v=%v", 50, k, v)
+	}
+
+	tests := []struct {
+		k                 int
+		floor, ceiling    int
+		hasFloor, hasCeil bool
+	}{
+		{5, 0, 10, false, true},
+		{10, 10, 10, true, true},
+		{25, 20, 30, true, true},
+		{50, 50, 50, true, true},
+		{55, 50, 0, true, false},
+	}
+
+	for _, tt := range tests {
+		if k, _, ok := tree.Floor(tt.k); ok != tt.hasFloor || ok && k != tt.floor {
+			t.Errorf("Floor(%v) expected %v (%t), but was %v (%t)", tt.k, tt.floor, tt.hasFloor, k, ok)
+		}
+
+		if k, _, ok := tree.Ceiling(tt.k); ok != tt.hasCeil || ok && k != tt.ceiling {
+			t.Errorf("Ceiling(%v) expected %v (%t), but was %v (%t)", tt.k, tt.ceiling, tt.hasCeil, k, ok)
+		}
+	}
+}
+
+func TestTraverse(t *testing.T) {
+	tree := NewFunc[string, int](func(a, b string) int {
+		// order by length, then alphabetically
+		if len(a) != len(b) {
+			return len(a) - len(b)
+		}
+		if a < b {
+			return -1
+		} else if a > b {
+			return 1
+		}
+		return 0
+	})
+
+	for i, k := range []string{"ccc", "a", "bb", "aa", "b"} {
+		tree.Insert(k, i)
+	}
+
+	var keys []string
+	for k := range tree.All() {
+		keys = append(keys, k)
+	}
+
+	if s := fmt.Sprint(keys); s != "[a b aa bb ccc]" {
+		t.Errorf("Unexpected in order traversal %v", s)
+	}
+
+	var values []int
+	for v := range tree.Traverse(InOrder) {
+		values = append(values, v)
+	}
+
+	if s := fmt.Sprint(values); s != "[1 4 3 2 0]" {
+		t.Errorf("Unexpected in order values %v", s)
+	}
+
+	n := 0
+	for range tree.Walk(PreOrder) {
+		if n++; n == 2 {
+			break
+		}
+	}
+
+	if n != 2 {
+		t.Errorf("Expected to stop after %v elements, but traversed %v", 2, n)
+	}
+}
+
+func TestClear(t *testing.T) {
+	tree := New[int, int]()
+	for _, i := range []int{5, 3, 7, 4, 6} {
+		tree.Insert(i, i)
+	}
+
+	tree.Clear()
+
+	if c := tree.Len(); c != 0 {
+		t.Errorf("Expected tree to be empty, but has %v elements", c)
+	}
+
+	if _, ok := tree.Find(5); ok {
+		t.Error("No elements expected in the tree")
+	}
+}
+
+// testInvariants fails the test if the tree is not a valid red-black tree.
+func testInvariants[K, V any](t *testing.T, tree *Tree[K, V]) {
+	t.Helper()
+
+	if err := tree.check(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// check verifies the red-black and search tree invariants.
+func (t *Tree[K, V]) check() error {
+	if isRed(t.root) {
+		return errors.New("root is red")
+	}
+
+	n, _, err := t.checkNode(t.root, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if n != t.count {
+		return fmt.Errorf("tree has %v nodes, but a count of %v", n, t.count)
+	}
+
+	return nil
+}
+
+// checkNode returns the number of nodes and the black height of a subtree,
+// verifying that all keys are within the (lo, hi) bounds.
+func (t *Tree[K, V]) checkNode(n *node[K, V], lo, hi *K) (count, black int, err error) {
+	if n == nil {
+		return 0, 1, nil
+	}
+
+	if lo != nil && t.cmp(n.key, *lo) <= 0 || hi != nil && t.cmp(n.key, *hi) >= 0 {
+		return 0, 0, fmt.Errorf("key %v is out of order", n.key)
+	}
+
+	if isRed(n.r) {
+		return 0, 0, fmt.Errorf("key %v has a red right link", n.key)
+	}
+
+	if isRed(n) && isRed(n.l) {
+		return 0, 0, fmt.Errorf("key %v has two red links in a row", n.key)
+	}
+
+	lc, lb, err := t.checkNode(n.l, lo, &n.key)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	rc, rb, err := t.checkNode(n.r, &n.key, hi)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if lb != rb {
+		return 0, 0, fmt.Errorf("key %v has unbalanced black heights %v and %v", n.key, lb, rb)
+	}
+
+	if !n.red {
+		lb++
+	}
+
+	return lc + rc + 1, lb, nil
+}
+
+// height returns the height of a subtree.
+func height[K, V any](n *node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+
+	return 1 + max(height(n.l), height(n.r))
+}
+
+func BenchmarkInsert(b *testing.B) {
+	tree := New[int, int]()
+	for _, i := range rand.Perm(b.N) {
+		tree.Insert(i, i)
+	}
+}
+
+func BenchmarkInsertSorted(b *testing.B) {
+	tree := New[int, int]()
+	for i := 0; i < b.N; i++ {
+		tree.Insert(i, i)
+	}
+}
+
+func BenchmarkDelete(b *testing.B) {
+	tree := New[int, int]()
+	for _, i := range rand.Perm(b.N) {
+		tree.Insert(i, i)
+	}
+
+	b.ResetTimer()
+	for _, i := range rand.Perm(b.N) {
+		tree.Delete(i)
+	}
+}
+
+func BenchmarkFind(b *testing.B) {
+	tree := New[int, int]()
+	for _, i := range rand.Perm(b.N) {
+		tree.Insert(i, i)
+	}
+
+	b.ResetTimer()
+	for _, i := range rand.Perm(b.N) {
+		tree.Find(i)
+	}
+}
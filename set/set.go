@@ -1,9 +1,243 @@
 // Package set implements a hashset.
 package set
 
-// S is the internal representation of the data structure.
+import "iter"
+
+// errModified is the panic raised when a set is modified during iteration.
+const errModified = "set: modified during iteration"
+
+// Set is the internal representation of a set of elements of type T.
+// The zero value is an empty set ready to use.
+// A set is not safe for concurrent use.
+type Set[T comparable] struct {
+	s map[T]struct{}
+	// gen is bumped on every modification so iterators can detect them
+	gen int
+}
+
+// Of returns a new set containing the given elements.
+// O(n)
+func Of[T comparable](xs ...T) *Set[T] {
+	return FromSlice(xs)
+}
+
+// FromSlice returns a new set containing the elements of the slice.
+// O(n)
+func FromSlice[T comparable](xs []T) *Set[T] {
+	ns := &Set[T]{s: make(map[T]struct{}, len(xs))}
+	for _, e := range xs {
+		ns.s[e] = struct{}{}
+	}
+
+	return ns
+}
+
+// Union returns the union of sets s and t in a new set.
+// If t is nil, the result is a copy of s.
+// O(n+m)
+func (s *Set[T]) Union(t *Set[T]) *Set[T] {
+	if t == nil {
+		return s.clone()
+	}
+
+	ns := &Set[T]{s: make(map[T]struct{}, s.Len()+t.Len())}
+
+	for e := range s.s {
+		ns.s[e] = struct{}{}
+	}
+
+	for e := range t.s {
+		ns.s[e] = struct{}{}
+	}
+
+	return ns
+}
+
+// Intersect returns the intersection of sets s and t in a new set.
+// If t is nil or empty, the result is an empty set.
+// O(min(n,m))
+func (s *Set[T]) Intersect(t *Set[T]) *Set[T] {
+	ns := new(Set[T])
+
+	if t == nil || t.Len() == 0 {
+		return ns
+	}
+
+	// find the smaller set to iterate through
+	ss, ls := s, t
+	if t.Len() < s.Len() {
+		ss, ls = t, s
+	}
+
+	for e := range ss.s {
+		if ls.Has(e) {
+			ns.Add(e)
+		}
+	}
+
+	return ns
+}
+
+// Diff returns the difference between sets s and t in a new set.
+// If t is nil or empty, the result is a copy of s.
+// O(n)
+func (s *Set[T]) Diff(t *Set[T]) *Set[T] {
+	if t == nil || t.Len() == 0 {
+		return s.clone()
+	}
+
+	ns := new(Set[T])
+
+	for e := range s.s {
+		if !t.Has(e) {
+			ns.Add(e)
+		}
+	}
+
+	return ns
+}
+
+// SymetricDiff returns a new set with elements from one set or the other, but not both.
+// If t is nil, the result is a copy of s.
+// O(n+m)
+func (s *Set[T]) SymetricDiff(t *Set[T]) *Set[T] {
+	if t == nil {
+		return s.clone()
+	}
+
+	ns := s.Diff(t)
+	nt := t.Diff(s)
+
+	return ns.Union(nt)
+}
+
+// IsSubset returns true if set s is a subset of set t.
+// O(n)
+func (s *Set[T]) IsSubset(t *Set[T]) bool {
+	if t == nil || s.Len() > t.Len() {
+		return false
+	}
+
+	return s.within(t)
+}
+
+// IsProperSubset returns true if set s is a proper subset of set t.
+// O(n)
+func (s *Set[T]) IsProperSubset(t *Set[T]) bool {
+	if t == nil || s.Len() >= t.Len() {
+		return false
+	}
+
+	return s.within(t)
+}
+
+// Equals returns true if the two sets contain the same elements.
+// O(n)
+func (s *Set[T]) Equals(t *Set[T]) bool {
+	if t == nil || s.Len() != t.Len() {
+		return false
+	}
+
+	return s.within(t)
+}
+
+// clone returns a new set with the elements of s.
+func (s *Set[T]) clone() *Set[T] {
+	ns := &Set[T]{s: make(map[T]struct{}, s.Len())}
+	for e := range s.s {
+		ns.s[e] = struct{}{}
+	}
+
+	return ns
+}
+
+// within returns true if every element of s is in t.
+func (s *Set[T]) within(t *Set[T]) bool {
+	for e := range s.s {
+		if !t.Has(e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Has returns true if the set contains the given element.
+// O(1)
+func (s *Set[T]) Has(e T) bool {
+	_, f := s.s[e]
+	return f
+}
+
+// Len returns the number of elements in the set.
+// O(1)
+func (s *Set[T]) Len() int {
+	return len(s.s)
+}
+
+// IsEmpty returns true if the set has no elements.
+// O(1)
+func (s *Set[T]) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+// Clear removes all elements from the set.
+// O(n)
+func (s *Set[T]) Clear() {
+	clear(s.s)
+	s.gen++
+}
+
+// All returns an iterator over the elements of the set in no particular order.
+// The set must not be modified while iterating, doing so panics.
+// O(n)
+func (s *Set[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		gen := s.gen
+		for e := range s.s {
+			if !yield(e) {
+				return
+			}
+
+			if s.gen != gen {
+				panic(errModified)
+			}
+		}
+	}
+}
+
+// Add adds a new element to the set and returns true if it was not already present.
+// O(1)
+func (s *Set[T]) Add(e T) bool {
+	if s.Has(e) {
+		return false
+	}
+
+	if s.s == nil {
+		s.s = make(map[T]struct{})
+	}
+	s.s[e] = struct{}{}
+	s.gen++
+
+	return true
+}
+
+// Remove removes an element from the set and returns true if the value previously existed.
+// O(1)
+func (s *Set[T]) Remove(e T) (f bool) {
+	if _, f = s.s[e]; f {
+		delete(s.s, e)
+		s.gen++
+	}
+
+	return
+}
+
+// S is a set of untyped elements.
+// It is kept for compatibility, new code should use Set.
 type S struct {
-	s map[interface{}]bool
+	s   map[interface{}]bool
+	gen int
 }
 
 // Init initializes the set data structure.
@@ -172,27 +406,47 @@ func (s *S) Clear() {
 	for v := range s.s {
 		delete(s.s, v)
 	}
+	s.gen++
 }
 
 // Iter provides an iterator over the set.
+// The elements are collected before returning, so the channel
+// is a snapshot of the set. New code should prefer All.
 // O(n)
 func (s *S) Iter() <-chan interface{} {
 	c := make(chan interface{}, s.Len())
-	go func() {
-		for e := range s.s {
-			c <- e
-		}
-		close(c)
-	}()
+	for e := range s.s {
+		c <- e
+	}
+	close(c)
 
 	return c
 }
 
+// All returns an iterator over the elements of the set in no particular order.
+// The set must not be modified while iterating, doing so panics.
+// O(n)
+func (s *S) All() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		gen := s.gen
+		for e := range s.s {
+			if !yield(e) {
+				return
+			}
+
+			if s.gen != gen {
+				panic(errModified)
+			}
+		}
+	}
+}
+
 // Add adds a new element to the set and returns true if the value previously existed.
 // O(1)
 func (s *S) Add(e interface{}) (f bool) {
 	if !s.s[e] {
 		s.s[e] = true
+		s.gen++
 		f = true
 	}
 
@@ -202,8 +456,10 @@ func (s *S) Add(e interface{}) (f bool) {
 // Remove removes an element from the set and returns true if the value previously existed.
 // O(1)
 func (s *S) Remove(e interface{}) (f bool) {
-	_, f = s.s[e]
-	delete(s.s, e)
+	if _, f = s.s[e]; f {
+		delete(s.s, e)
+		s.gen++
+	}
 
 	return
 }
@@ -271,6 +271,178 @@ func TestIter(t *testing.T) {
 	}
 }
 
+func TestSetAddRemoveHas(t *testing.T) {
+	var s Set[string]
+
+	testLen(t, s.Len(), 0)
+	if s.Has("a") || s.Remove("a") {
+		t.Errorf("The zero set should not contain any elements\n")
+	}
+
+	if !s.Add("a") {
+		t.Errorf("'a' should have been added to the set\n")
+	}
+
+	if s.Add("a") {
+		t.Errorf("'a' already exited in the set, so it should not be added again\n")
+	}
+
+	s.Add("b")
+	testLen(t, s.Len(), 2)
+
+	if !s.Remove("b") || s.Has("b") {
+		t.Errorf("'b' should have been removed from the set\n")
+	}
+
+	s.Clear()
+	if !s.IsEmpty() {
+		t.Errorf("Set should be empty after Clear\n")
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+	s1 := Of(0, 1, 2, 3)
+	s2 := FromSlice([]int{2, 3, 4})
+
+	tests := []struct {
+		name     string
+		actual   *Set[int]
+		expected *Set[int]
+	}{
+		{"Union", s1.Union(s2), Of(0, 1, 2, 3, 4)},
+		{"Intersect", s1.Intersect(s2), Of(2, 3)},
+		{"Diff", s1.Diff(s2), Of(0, 1)},
+		{"SymetricDiff", s1.SymetricDiff(s2), Of(0, 1, 4)},
+		{"Intersect nil", s1.Intersect(nil), Of[int]()},
+		{"Intersect empty", s1.Intersect(new(Set[int])), Of[int]()},
+		{"Union nil", s1.Union(nil), s1},
+		{"Diff nil", s1.Diff(nil), s1},
+		{"Diff empty", s1.Diff(new(Set[int])), s1},
+		{"SymetricDiff nil", s1.SymetricDiff(nil), s1},
+	}
+
+	for _, tt := range tests {
+		if !tt.actual.Equals(tt.expected) {
+			t.Errorf("%v: expected %v elements, got %v\n", tt.name, tt.expected.Len(), tt.actual.Len())
+		}
+	}
+
+	for _, ns := range []*Set[int]{s1.Union(nil), s1.Diff(nil), s1.SymetricDiff(nil)} {
+		if ns.Add(5); s1.Has(5) {
+			t.Errorf("Modifying the result with a null set should not modify the set\n")
+		}
+	}
+}
+
+func TestSetSubset(t *testing.T) {
+	s1 := Of("a", "c")
+	s2 := Of("a", "b", "c")
+
+	if !s1.IsSubset(s2) || !s1.IsProperSubset(s2) {
+		t.Errorf("S1 is not a subset of S2 as expected\n")
+	}
+
+	if !s2.IsSubset(s2) || s2.IsProperSubset(s2) {
+		t.Errorf("A set should be a subset, but not a proper subset, of itself\n")
+	}
+
+	if s2.IsSubset(s1) || s1.IsSubset(nil) || s1.IsProperSubset(nil) {
+		t.Errorf("Unexpected subset relation\n")
+	}
+
+	if s1.Equals(s2) || s1.Equals(nil) {
+		t.Errorf("S1 should not be equal to S2\n")
+	}
+}
+
+func TestSetAll(t *testing.T) {
+	s := new(Set[int])
+	for i := 0; i < 100; i++ {
+		s.Add(i)
+	}
+
+	seen := new(Set[int])
+	for e := range s.All() {
+		seen.Add(e)
+	}
+
+	if !seen.Equals(s) {
+		t.Errorf("Expected to iterate %v elements, but iterated %v\n", s.Len(), seen.Len())
+	}
+
+	n := 0
+	for range s.All() {
+		if n++; n == 10 {
+			break
+		}
+	}
+
+	if n != 10 {
+		t.Errorf("Expected to stop after %v elements, but iterated %v\n", 10, n)
+	}
+}
+
+func TestAll_ModifiedDuringIteration(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(s *Set[int])
+	}{
+		{"Add", func(s *Set[int]) { s.Add(-1) }},
+		{"Remove", func(s *Set[int]) { s.Remove(0) }},
+		{"Clear", func(s *Set[int]) { s.Clear() }},
+	}
+
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%v during iteration should have panicked\n", tt.name)
+				}
+			}()
+
+			s := Of(0, 1, 2)
+			for range s.All() {
+				tt.f(s)
+			}
+		}()
+	}
+
+	// operations that don't change the set are allowed
+	s := Of(0, 1, 2)
+	for e := range s.All() {
+		s.Add(e)
+		s.Remove(-1)
+	}
+}
+
+func TestIter_Snapshot(t *testing.T) {
+	s := new(S)
+	s.Init()
+	s.Add(1)
+	s.Add(2)
+
+	c := s.Iter()
+	s.Add(3)
+
+	n := 0
+	for range c {
+		n++
+	}
+
+	if n != 2 {
+		t.Errorf("Expected to iterate %v elements, but iterated %v\n", 2, n)
+	}
+
+	m := 0
+	for range s.All() {
+		m++
+	}
+
+	if m != 3 {
+		t.Errorf("Expected to iterate %v elements, but iterated %v\n", 3, m)
+	}
+}
+
 func testLen(t *testing.T, actual, expected int) {
 	if actual != expected {
 		t.Errorf("Expected set length to be %v, instead was %v\n", expected, actual)
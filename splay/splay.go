@@ -0,0 +1,503 @@
+// Package splay implements a self-adjusting splay tree.
+//
+// Every access moves the accessed key to the root of the tree, so
+// frequently used keys stay close to the top. Since lookups modify
+// the tree, a tree is not safe for concurrent use, even for reads.
+package splay
+
+import (
+	"cmp"
+	"iter"
+
+	"github.com/cosn/collections/stack"
+)
+
+// Tree is the internal representation of a splay tree.
+// Trees must be created with New or NewFunc.
+type Tree[K, V any] struct {
+	root *node[K, V]
+	cmp  func(a, b K) int
+}
+
+// node is the internal representation of a splay tree node.
+// Every node tracks the size of its subtree, which allows
+// splitting a tree without walking it.
+type node[K, V any] struct {
+	key  K
+	val  V
+	l, r *node[K, V]
+	size int
+}
+
+// TraversalType represents one of the three known traversals.
+type TraversalType int
+
+const (
+	InOrder TraversalType = iota
+	PreOrder
+	PostOrder
+)
+
+// New returns an empty tree ordered by the natural ordering of its keys.
+// O(1)
+func New[K cmp.Ordered, V any]() *Tree[K, V] {
+	return NewFunc[K, V](cmp.Compare[K])
+}
+
+// NewFunc returns an empty tree ordered by the given comparator, which must
+// return a negative number when a < b, a positive number when a > b and
+// zero when a == b.
+// O(1)
+func NewFunc[K, V any](cmp func(a, b K) int) *Tree[K, V] {
+	return &Tree[K, V]{cmp: cmp}
+}
+
+// Insert adds a given key+value to the tree and returns true if it was added.
+// Amortized: O(log(n))
+func (t *Tree[K, V]) Insert(k K, v V) bool {
+	n := &node[K, V]{key: k, val: v, size: 1}
+	if t.root == nil {
+		t.root = n
+		return true
+	}
+
+	r := t.splay(t.root, k)
+	c := t.cmp(k, r.key)
+	if c == 0 {
+		t.root = r
+		return false
+	}
+
+	// the splayed root becomes a child of the new node
+	if c < 0 {
+		n.l, n.r = r.l, r
+		r.l = nil
+	} else {
+		n.l, n.r = r, r.r
+		r.r = nil
+	}
+	r.update()
+	n.update()
+	t.root = n
+
+	return true
+}
+
+// Delete removes a given key from the tree and returns true if it was removed.
+// Amortized: O(log(n))
+func (t *Tree[K, V]) Delete(k K) bool {
+	if t.root == nil {
+		return false
+	}
+
+	t.root = t.splay(t.root, k)
+	if t.cmp(k, t.root.key) != 0 {
+		return false
+	}
+
+	t.root = t.join(t.root.l, t.root.r, k)
+
+	return true
+}
+
+// Find returns the value found at the given key and
+// true if the tree contains the key.
+// Amortized: O(log(n))
+func (t *Tree[K, V]) Find(k K) (v V, found bool) {
+	if t.root == nil {
+		return v, false
+	}
+
+	t.root = t.splay(t.root, k)
+	if t.cmp(k, t.root.key) != 0 {
+		return v, false
+	}
+
+	return t.root.val, true
+}
+
+// Split divides the tree around a key, returning a tree with the keys
+// smaller than k and a tree with the keys greater than or equal to k.
+// The nodes are moved, not copied, so the tree is left empty.
+// Amortized: O(log(n))
+func (t *Tree[K, V]) Split(k K) (left, right *Tree[K, V]) {
+	left, right = &Tree[K, V]{cmp: t.cmp}, &Tree[K, V]{cmp: t.cmp}
+	if t.root == nil {
+		return
+	}
+
+	r := t.splay(t.root, k)
+	if t.cmp(r.key, k) < 0 {
+		right.root, r.r = r.r, nil
+		left.root = r
+	} else {
+		left.root, r.l = r.l, nil
+		right.root = r
+	}
+	r.update()
+	t.root = nil
+
+	return
+}
+
+// Join moves all the keys of o into this tree and returns true, as long as
+// they are all greater than the keys in this tree. Otherwise false is
+// returned and both trees keep their keys. The nodes are reused, not copied.
+// Amortized: O(log(n))
+func (t *Tree[K, V]) Join(o *Tree[K, V]) bool {
+	if o == nil || o.root == nil {
+		return true
+	}
+
+	if t.root != nil {
+		maxKey := t.root.maxKey()
+		o.root = o.splay(o.root, maxKey)
+		if o.root.l != nil || t.cmp(maxKey, o.root.key) >= 0 {
+			// the smallest key of o does not come after this tree
+			return false
+		}
+	}
+
+	t.root = t.join(t.root, o.root, o.root.minKey())
+	o.root = nil
+
+	return true
+}
+
+// join combines two subtrees, where all keys in l are smaller than k
+// and all the keys in r are greater than or equal to k.
+func (t *Tree[K, V]) join(l, r *node[K, V], k K) *node[K, V] {
+	if l == nil {
+		return r
+	}
+
+	// splaying the largest key brings it to the root
+	// of the left subtree, so it has no right child
+	l = t.splay(l, k)
+	l.r = r
+	l.update()
+
+	return l
+}
+
+// Min returns the smallest key in the tree and its value.
+// The boolean is false if the tree is empty.
+// Amortized: O(log(n))
+func (t *Tree[K, V]) Min() (k K, v V, ok bool) {
+	if t.root == nil {
+		return k, v, false
+	}
+
+	t.root = t.splay(t.root, t.root.minKey())
+
+	return t.root.key, t.root.val, true
+}
+
+// Max returns the largest key in the tree and its value.
+// The boolean is false if the tree is empty.
+// Amortized: O(log(n))
+func (t *Tree[K, V]) Max() (k K, v V, ok bool) {
+	if t.root == nil {
+		return k, v, false
+	}
+
+	t.root = t.splay(t.root, t.root.maxKey())
+
+	return t.root.key, t.root.val, true
+}
+
+// Len returns the number of elements in the tree.
+// O(1)
+func (t *Tree[K, V]) Len() int {
+	return t.root.len()
+}
+
+// Clear removes all the nodes from the tree.
+// O(1)
+func (t *Tree[K, V]) Clear() {
+	t.root = nil
+}
+
+// Traverse returns a channel with the values of the tree in pre, in or
+// post order. Like Walk, it does not splay the tree. Every value is sent
+// before the channel is returned closed, so it can be dropped partway.
+// O(n)
+func (t *Tree[K, V]) Traverse(tt TraversalType) <-chan V {
+	c := make(chan V, t.Len())
+	for _, v := range t.Walk(tt) {
+		c <- v
+	}
+	close(c)
+
+	return c
+}
+
+// Walk returns an iterator over the key+value pairs of the tree
+// in the order given by the traversal type. Walking the tree does
+// not splay it.
+// O(n)
+func (t *Tree[K, V]) Walk(tt TraversalType) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		switch tt {
+		case InOrder:
+			inOrder(t.root, yield)
+		case PreOrder:
+			preOrder(t.root, yield)
+		case PostOrder:
+			postOrder(t.root, yield)
+		}
+	}
+}
+
+// All returns an iterator over the key+value pairs of the tree
+// in ascending key order.
+// O(n)
+func (t *Tree[K, V]) All() iter.Seq2[K, V] {
+	return t.Walk(InOrder)
+}
+
+// Backward returns an iterator over the key+value pairs of the tree
+// in descending key order.
+// O(n)
+func (t *Tree[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		reverseOrder(t.root, yield)
+	}
+}
+
+// Keys returns an iterator over the keys of the tree in ascending order.
+// O(n)
+func (t *Tree[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range t.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values of the tree in ascending key order.
+// O(n)
+func (t *Tree[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range t.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// inOrder yields the left, parent, right nodes.
+// It returns false once yield asks to stop. The traversals keep their own
+// stack, since keys inserted in order leave the tree as deep as it is long.
+func inOrder[K, V any](n *node[K, V], yield func(K, V) bool) bool {
+	var s stack.Stack[*node[K, V]]
+
+	for {
+		for ; n != nil; n = n.l {
+			s.Push(n)
+		}
+
+		var ok bool
+		if n, ok = s.Pop(); !ok {
+			return true
+		}
+
+		if !yield(n.key, n.val) {
+			return false
+		}
+		n = n.r
+	}
+}
+
+// reverseOrder yields the right, parent, left nodes.
+func reverseOrder[K, V any](n *node[K, V], yield func(K, V) bool) bool {
+	var s stack.Stack[*node[K, V]]
+
+	for {
+		for ; n != nil; n = n.r {
+			s.Push(n)
+		}
+
+		var ok bool
+		if n, ok = s.Pop(); !ok {
+			return true
+		}
+
+		if !yield(n.key, n.val) {
+			return false
+		}
+		n = n.l
+	}
+}
+
+// preOrder yields the parent, left, right nodes.
+func preOrder[K, V any](n *node[K, V], yield func(K, V) bool) bool {
+	var s stack.Stack[*node[K, V]]
+	if n != nil {
+		s.Push(n)
+	}
+
+	for n, ok := s.Pop(); ok; n, ok = s.Pop() {
+		if !yield(n.key, n.val) {
+			return false
+		}
+
+		// the right child is pushed first, so the left one comes out first
+		if n.r != nil {
+			s.Push(n.r)
+		}
+		if n.l != nil {
+			s.Push(n.l)
+		}
+	}
+
+	return true
+}
+
+// postOrder yields the left, right, parent nodes.
+func postOrder[K, V any](n *node[K, V], yield func(K, V) bool) bool {
+	var s stack.Stack[*node[K, V]]
+	// last is the previous node yielded, which tells whether
+	// the right subtree of the node on top was already visited
+	var last *node[K, V]
+
+	for {
+		for ; n != nil; n = n.l {
+			s.Push(n)
+		}
+
+		p, ok := s.Peek()
+		if !ok {
+			return true
+		}
+
+		if p.r != nil && p.r != last {
+			n = p.r
+			continue
+		}
+
+		if !yield(p.key, p.val) {
+			return false
+		}
+		last = p
+		s.Pop()
+	}
+}
+
+// splay moves the node with key k, or the last node visited while looking
+// for it, to the root of the subtree and returns the new root.
+// It is the top-down splay of Sleator and Tarjan, which keeps track of
+// subtree sizes while splitting the tree into a left and right part.
+func (t *Tree[K, V]) splay(n *node[K, V], k K) *node[K, V] {
+	var header node[K, V]
+	l, r := &header, &header
+	// sizes of the left and right parts assembled so far
+	ls, rs := 0, 0
+
+	for {
+		c := t.cmp(k, n.key)
+		if c < 0 {
+			if n.l == nil {
+				break
+			}
+
+			if t.cmp(k, n.l.key) < 0 {
+				// zig-zig: rotate right
+				x := n.l
+				n.l = x.r
+				x.r = n
+				n.update()
+				n = x
+				if n.l == nil {
+					break
+				}
+			}
+
+			// link right
+			r.l = n
+			r = n
+			n = n.l
+			rs += 1 + r.r.len()
+		} else if c > 0 {
+			if n.r == nil {
+				break
+			}
+
+			if t.cmp(k, n.r.key) > 0 {
+				// zig-zig: rotate left
+				x := n.r
+				n.r = x.l
+				x.l = n
+				n.update()
+				n = x
+				if n.r == nil {
+					break
+				}
+			}
+
+			// link left
+			l.r = n
+			l = n
+			n = n.r
+			ls += 1 + l.l.len()
+		} else {
+			break
+		}
+	}
+
+	ls += n.l.len()
+	rs += n.r.len()
+	n.size = ls + rs + 1
+
+	// the nodes linked into the left and right parts still have their
+	// old sizes, so correct them along the paths before reassembling
+	l.r, r.l = nil, nil
+	for y := header.r; y != nil; y = y.r {
+		y.size = ls
+		ls -= 1 + y.l.len()
+	}
+	for y := header.l; y != nil; y = y.l {
+		y.size = rs
+		rs -= 1 + y.r.len()
+	}
+
+	l.r, r.l = n.l, n.r
+	n.l, n.r = header.r, header.l
+
+	return n
+}
+
+// len returns the size of a possibly nil subtree.
+func (n *node[K, V]) len() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.size
+}
+
+// update recomputes the size of a node from its children.
+func (n *node[K, V]) update() {
+	n.size = 1 + n.l.len() + n.r.len()
+}
+
+// minKey returns the smallest key in the subtree.
+func (n *node[K, V]) minKey() K {
+	for n.l != nil {
+		n = n.l
+	}
+
+	return n.key
+}
+
+// maxKey returns the largest key in the subtree.
+func (n *node[K, V]) maxKey() K {
+	for n.r != nil {
+		n = n.r
+	}
+
+	return n.key
+}
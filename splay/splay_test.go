@@ -0,0 +1,406 @@
+package splay
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"slices"
+	"testing"
+
+	"github.com/cosn/collections/bst"
+)
+
+func TestInsertFind(t *testing.T) {
+	expected := []int{5, 3, 7, 4, 6}
+	tree := New[int, int]()
+
+	for _, i := range expected {
+		if !tree.Insert(i, i) {
+			t.Errorf("Element %v should have been added to the tree", i)
+		}
+	}
+
+	for _, i := range expected {
+		if v, ok := tree.Find(i); !ok || v != i {
+			t.Errorf("Element %v expected to be in the tree, but was not", i)
+		}
+
+		if tree.root.key != i {
+			t.Errorf("Element %v expected to be splayed to the root, but found %v", i, tree.root.key)
+		}
+	}
+
+	if tree.Insert(4, 44) {
+		t.Error("Duplicate elements should not be added")
+	}
+
+	if v, _ := tree.Find(4); v == 44 {
+		t.Error("Previously inserted elements should not be updated")
+	}
+
+	if _, ok := tree.Find(8); ok {
+		t.Errorf("Element %v should not have been found", 8)
+	}
+
+	if c := tree.Len(); c != len(expected) {
+		t.Errorf("Tree expected to have %v elements, but has %v instead", len(expected), c)
+	}
+	testInvariants(t, tree)
+}
+
+func TestDelete(t *testing.T) {
+	tree := New[int, int]()
+
+	if tree.Delete(1) {
+		t.Error("Deleting from an empty tree should fail")
+	}
+
+	for _, i := range rand.Perm(300) {
+		tree.Insert(i, i)
+	}
+
+	for j, i := range rand.Perm(300) {
+		if !tree.Delete(i) {
+			t.Fatalf("Element %v should have been removed", i)
+		}
+
+		if tree.Delete(i) {
+			t.Fatalf("Element %v should not be deleted twice", i)
+		}
+
+		if c := tree.Len(); c != 300-j-1 {
+			t.Fatalf("Tree expected to have %v elements, but has %v instead", 300-j-1, c)
+		}
+		testInvariants(t, tree)
+	}
+}
+
+func TestRandomOperations(t *testing.T) {
+	tree := New[int, int]()
+	m := make(map[int]bool)
+
+	for i := 0; i < 5000; i++ {
+		k := rand.Intn(200)
+		switch rand.Intn(3) {
+		case 0:
+			if tree.Delete(k) != m[k] {
+				t.Fatalf("Delete(%v) disagrees with the expected contents", k)
+			}
+			delete(m, k)
+		case 1:
+			if tree.Insert(k, k) == m[k] {
+				t.Fatalf("Insert(%v) disagrees with the expected contents", k)
+			}
+			m[k] = true
+		default:
+			if _, ok := tree.Find(k); ok != m[k] {
+				t.Fatalf("Find(%v) disagrees with the expected contents", k)
+			}
+		}
+	}
+
+	if tree.Len() != len(m) {
+		t.Errorf("Tree expected to have %v elements, but has %v instead", len(m), tree.Len())
+	}
+	testInvariants(t, tree)
+}
+
+func TestMinMax(t *testing.T) {
+	tree := New[string, int]()
+
+	if _, _, ok := tree.Min(); ok {
+		t.Error("Min of an empty tree should fail")
+	}
+
+	for i, k := range []string{"m", "c", "x", "a", "e"} {
+		tree.Insert(k, i)
+	}
+
+	if k, v, _ := tree.Min(); k != "a" || v != 3 {
+		t.Errorf("Min expected to be %v, but was %v=%v", "a", k, v)
+	}
+
+	if k, v, _ := tree.Max(); k != "x" || v != 2 {
+		t.Errorf("Max expected to be %v, but was %v=%v", "x", k, v)
+	}
+	testInvariants(t, tree)
+}
+
+func TestSplitJoin(t *testing.T) {
+	tests := []struct {
+		k           int
+		left, right int
+	}{
+		{-1, 0, 100},
+		{0, 0, 100},
+		{50, 50, 50},
+		{99, 99, 1},
+		{100, 100, 0},
+	}
+
+	for _, tt := range tests {
+		tree := New[int, int]()
+		for _, i := range rand.Perm(100) {
+			tree.Insert(i, i)
+		}
+
+		left, right := tree.Split(tt.k)
+		if tree.Len() != 0 || tree.root != nil {
+			t.Errorf("Tree expected to be empty after Split, but has %v elements", tree.Len())
+		}
+
+		if left.Len() != tt.left || right.Len() != tt.right {
+			t.Errorf("Split(%v) expected %v and %v elements, but got %v and %v", tt.k, tt.left, tt.right, left.Len(), right.Len())
+		}
+		testInvariants(t, left)
+		testInvariants(t, right)
+
+		for k := range left.All() {
+			if k >= tt.k {
+				t.Errorf("Split(%v) left key %v is out of range", tt.k, k)
+			}
+		}
+
+		for k := range right.All() {
+			if k < tt.k {
+				t.Errorf("Split(%v) right key %v is out of range", tt.k, k)
+			}
+		}
+
+		if !left.Join(right) {
+			t.Errorf("Split(%v) halves should join back", tt.k)
+		}
+
+		if left.Len() != 100 || right.Len() != 0 {
+			t.Errorf("Join expected to move all elements, but got %v and %v", left.Len(), right.Len())
+		}
+		testInvariants(t, left)
+
+		i := 0
+		for k := range left.All() {
+			if k != i {
+				t.Errorf("Expected to traverse %v, but instead traversed %v", i, k)
+			}
+			i++
+		}
+	}
+}
+
+func TestJoin_Overlapping(t *testing.T) {
+	a, b := New[int, int](), New[int, int]()
+	for _, i := range []int{1, 5, 9} {
+		a.Insert(i, i)
+	}
+	for _, i := range []int{7, 12} {
+		b.Insert(i, i)
+	}
+
+	if a.Join(b) {
+		t.Error("Trees with overlapping keys should not be joined")
+	}
+
+	if a.Len() != 3 || b.Len() != 2 {
+		t.Errorf("Trees should be unchanged, but have %v and %v elements", a.Len(), b.Len())
+	}
+	testInvariants(t, a)
+	testInvariants(t, b)
+
+	if !b.Join(New[int, int]()) || !New[int, int]().Join(b) {
+		t.Error("Joining with an empty tree should succeed")
+	}
+}
+
+func TestTraverse(t *testing.T) {
+	tree := New[int, string]()
+	for _, i := range []int{5, 3, 7, 4, 6} {
+		tree.Insert(i, fmt.Sprint(i))
+	}
+
+	var values []string
+	for v := range tree.Traverse(InOrder) {
+		values = append(values, v)
+	}
+
+	if !slices.Equal(values, []string{"3", "4", "5", "6", "7"}) {
+		t.Errorf("Unexpected in order values %v", values)
+	}
+
+	n := 0
+	for range tree.Walk(PostOrder) {
+		if n++; n == 2 {
+			break
+		}
+	}
+
+	if n != 2 {
+		t.Errorf("Expected to stop after %v elements, but traversed %v", 2, n)
+	}
+}
+
+func TestIterators_DoNotSplay(t *testing.T) {
+	tree := New[int, string]()
+	for _, i := range []int{5, 3, 7, 4, 6} {
+		tree.Insert(i, fmt.Sprint(i))
+	}
+
+	shape := preOrderKeys(tree)
+	root := tree.root
+
+	keys := slices.Collect(tree.Keys())
+	values := slices.Collect(tree.Values())
+	var backward []int
+	for k := range tree.Backward() {
+		backward = append(backward, k)
+	}
+
+	if !slices.Equal(keys, []int{3, 4, 5, 6, 7}) {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+	if !slices.Equal(values, []string{"3", "4", "5", "6", "7"}) {
+		t.Errorf("Unexpected values %v", values)
+	}
+	if !slices.Equal(backward, []int{7, 6, 5, 4, 3}) {
+		t.Errorf("Unexpected backward keys %v", backward)
+	}
+
+	if tree.root != root || !slices.Equal(preOrderKeys(tree), shape) {
+		t.Errorf("Iterating should not change the shape of the tree, expected %v but was %v", shape, preOrderKeys(tree))
+	}
+}
+
+func TestDegenerate(t *testing.T) {
+	// keys inserted in order build a left spine as deep as the tree,
+	// which a traversal with a frame per level would overflow
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 17))
+
+	const n = 1 << 13
+
+	tree := New[int, int]()
+	for i := 0; i < n; i++ {
+		tree.Insert(i, i)
+	}
+
+	depth := 0
+	for x := tree.root; x != nil; x = x.l {
+		depth++
+	}
+
+	if depth != n {
+		t.Fatalf("Sorted inserts expected to build a spine of %v nodes, but it has %v", n, depth)
+	}
+
+	for _, tt := range []TraversalType{InOrder, PreOrder, PostOrder} {
+		c := 0
+		for range tree.Walk(tt) {
+			c++
+		}
+
+		if c != n {
+			t.Errorf("Traversal %v expected %v elements, but traversed %v", tt, n, c)
+		}
+	}
+
+	i := n
+	for k := range tree.Backward() {
+		if i--; k != i {
+			t.Fatalf("Expected to traverse %v backward, but instead traversed %v", i, k)
+		}
+	}
+
+	if i != 0 {
+		t.Errorf("Backward expected %v elements, but traversed %v", n, n-i)
+	}
+}
+
+// preOrderKeys returns the keys of the tree in pre order, which
+// together with the in order keys determine the shape of the tree.
+func preOrderKeys[V any](tree *Tree[int, V]) []int {
+	var keys []int
+	for k := range tree.Walk(PreOrder) {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// testInvariants fails the test if the keys are out of order
+// or the subtree sizes are wrong.
+func testInvariants[K, V any](t *testing.T, tree *Tree[K, V]) {
+	t.Helper()
+
+	if err := tree.check(tree.root, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// check verifies the search tree order and the subtree sizes.
+func (t *Tree[K, V]) check(n *node[K, V], lo, hi *K) error {
+	if n == nil {
+		return nil
+	}
+
+	if lo != nil && t.cmp(n.key, *lo) <= 0 || hi != nil && t.cmp(n.key, *hi) >= 0 {
+		return fmt.Errorf("key %v is out of order", n.key)
+	}
+
+	if s := 1 + n.l.len() + n.r.len(); s != n.size {
+		return fmt.Errorf("key %v has size %v, expected %v", n.key, n.size, s)
+	}
+
+	if err := t.check(n.l, lo, &n.key); err != nil {
+		return err
+	}
+
+	return t.check(n.r, &n.key, hi)
+}
+
+// zipf returns count keys drawn from n keys, with a few keys
+// being accessed much more often than the rest.
+func zipf(n, count int) []int {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 1.2, 1, uint64(n-1))
+	// spread the hot keys across the tree
+	perm := r.Perm(n)
+
+	keys := make([]int, count)
+	for i := range keys {
+		keys[i] = perm[z.Uint64()]
+	}
+
+	return keys
+}
+
+const benchmarkSize = 1 << 16
+
+func BenchmarkInsert(b *testing.B) {
+	tree := New[int, int]()
+	for _, i := range rand.Perm(b.N) {
+		tree.Insert(i, i)
+	}
+}
+
+func BenchmarkFindZipf(b *testing.B) {
+	tree := New[int, int]()
+	for _, i := range rand.Perm(benchmarkSize) {
+		tree.Insert(i, i)
+	}
+	keys := zipf(benchmarkSize, b.N)
+
+	b.ResetTimer()
+	for _, k := range keys {
+		tree.Find(k)
+	}
+}
+
+func BenchmarkFindZipfBST(b *testing.B) {
+	tree := bst.New[int, int]()
+	for _, i := range rand.Perm(benchmarkSize) {
+		tree.Insert(i, i)
+	}
+	keys := zipf(benchmarkSize, b.N)
+
+	b.ResetTimer()
+	for _, k := range keys {
+		tree.Find(k)
+	}
+}
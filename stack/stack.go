@@ -1,72 +1,108 @@
 // Package stack implements a stack.
 package stack
 
-// S is the internal representation of the data structure.
-type S struct {
-	storage []interface{}
-	i       int
+// Stack is the internal representation of a stack of elements of type T.
+// The zero value is an empty stack ready to use.
+type Stack[T any] struct {
+	storage []T
 }
 
-// Init initializes the stack data structure.
-// A stack must be initialized before it can be used.
+// Init initializes the stack with capacity for size elements.
+// Calling Init is optional, but avoids resizing when the size is known.
 // O(1)
-func (s *S) Init(size int) {
-	// the stack needs to have at least capacity for 1 element
-	// defaulting here instead of panicing to allow for benchmarking
-	if size == 0 {
-		size = 1
-	}
-
-	s.storage = make([]interface{}, size)
-	s.i = -1
+func (s *Stack[T]) Init(size int) {
+	s.storage = make([]T, 0, size)
 }
 
 // Push adds a new element to the top of the stack.
+// Amortized: O(1)
+func (s *Stack[T]) Push(v T) {
+	s.storage = append(s.storage, v)
+}
+
+// Pop removes the top element from the stack.
+// The boolean is false if the stack was empty.
 // O(1)
-func (s *S) Push(v interface{}) {
-	// dynamically increase the size of storage as needed
-	if s.i+1 == cap(s.storage) {
-		ns := make([]interface{}, cap(s.storage)*2)
-		copy(ns, s.storage)
-		s.storage = ns
+func (s *Stack[T]) Pop() (v T, ok bool) {
+	i := len(s.storage) - 1
+	if i < 0 {
+		return v, false
 	}
 
-	s.i++
-	s.storage[s.i] = v
+	v = s.storage[i]
+	// clear the slot so the element can be garbage collected
+	var zero T
+	s.storage[i] = zero
+	s.storage = s.storage[:i]
+
+	return v, true
 }
 
-// Pop removes the top element from the stack.
-// O(1).
-func (s *S) Pop() interface{} {
-	if s.i < 0 {
-		return nil
+// Peek returns the top element from the stack without removing it.
+// The boolean is false if the stack is empty.
+// O(1)
+func (s *Stack[T]) Peek() (v T, ok bool) {
+	if len(s.storage) == 0 {
+		return v, false
 	}
 
-	v := s.storage[s.i]
-	s.storage[s.i] = nil
-	s.i--
+	return s.storage[len(s.storage)-1], true
+}
 
+// IsEmpty returns true if the stack has no elements.
+// O(1)
+func (s *Stack[T]) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+// Len returns the number of elements in the stack.
+// O(1)
+func (s *Stack[T]) Len() int {
+	return len(s.storage)
+}
+
+// S is a stack of untyped elements.
+// It is kept for compatibility, new code should use Stack.
+type S struct {
+	s Stack[interface{}]
+}
+
+// Init initializes the stack data structure.
+// O(1)
+func (s *S) Init(size int) {
+	s.s.Init(size)
+}
+
+// Push adds a new element to the top of the stack.
+// Amortized: O(1)
+func (s *S) Push(v interface{}) {
+	s.s.Push(v)
+}
+
+// Pop removes the top element from the stack.
+// Returns nil if the stack is empty.
+// O(1)
+func (s *S) Pop() interface{} {
+	v, _ := s.s.Pop()
 	return v
 }
 
 // Peek returns the top element from the stack without removing it.
+// Returns nil if the stack is empty.
 // O(1)
 func (s *S) Peek() interface{} {
-	if s.i < 0 {
-		return nil
-	}
-
-	return s.storage[s.i]
+	v, _ := s.s.Peek()
+	return v
 }
 
 // IsEmpty returns true if the stack has no elements.
 // O(1)
 func (s *S) IsEmpty() bool {
-	return s.Len() == 0
+	return s.s.IsEmpty()
 }
 
 // Len returns the number of elements in the stack.
 // O(1)
 func (s *S) Len() int {
-	return s.i + 1
+	return s.s.Len()
 }
@@ -84,6 +84,60 @@ func TestIsEmpty(t *testing.T) {
 	}
 }
 
+func TestStackPushPop(t *testing.T) {
+	var s Stack[int]
+
+	for i := 0; i < iterations; i++ {
+		s.Push(i)
+	}
+
+	for i := iterations - 1; i >= 0; i-- {
+		if v, ok := s.Pop(); !ok || v != i {
+			t.Errorf("Popping expected %v, got %v (%t)", i, v, ok)
+		}
+	}
+
+	if v, ok := s.Pop(); ok {
+		t.Errorf("Popping an empty stack should fail, got %v", v)
+	}
+}
+
+func TestStackPeek(t *testing.T) {
+	s := new(Stack[string])
+	s.Init(10)
+
+	if _, ok := s.Peek(); ok {
+		t.Error("Peeking an empty stack should fail")
+	}
+
+	s.Push("a")
+	s.Push("b")
+	if v, ok := s.Peek(); !ok || v != "b" {
+		t.Errorf("Peeking expected %v, got %v (%t)", "b", v, ok)
+	}
+
+	if l := s.Len(); l != 2 {
+		t.Errorf("Stack length was expected to be %v, but is %v", 2, l)
+	}
+}
+
+func TestStackNil(t *testing.T) {
+	var s Stack[*int]
+
+	s.Push(nil)
+	if s.IsEmpty() {
+		t.Error("Stack should not be empty")
+	}
+
+	if v, ok := s.Pop(); !ok || v != nil {
+		t.Errorf("Popping expected a stored nil, got %v (%t)", v, ok)
+	}
+
+	if !s.IsEmpty() {
+		t.Error("Stack should be empty")
+	}
+}
+
 func testPop(t *testing.T, s *S, e interface{}) {
 	if v := s.Pop(); v != e {
 		t.Errorf("Popping expected %v, got %v", e, v)
@@ -129,3 +183,11 @@ func BenchmarkPop(b *testing.B) {
 		s.Pop()
 	}
 }
+
+func BenchmarkStackPush(b *testing.B) {
+	var s Stack[int]
+
+	for i := 0; i < b.N; i++ {
+		s.Push(i)
+	}
+}
@@ -1,11 +1,30 @@
 // Package trie implements a trie.
 package trie
 
+import (
+	"cmp"
+	"errors"
+	"iter"
+	"slices"
+	"unicode/utf8"
+)
+
+var (
+	// ErrAlphabet is returned when inserting a word with characters
+	// outside of the trie's alphabet.
+	ErrAlphabet = errors.New("trie: word has characters outside of the alphabet")
+	// ErrInvalidUTF8 is returned when inserting a word that isn't valid UTF-8.
+	ErrInvalidUTF8 = errors.New("trie: word is not valid UTF-8")
+)
+
 // T is the internal representation of a trie.
 type T struct {
 	root  *node
 	words int
 	size  rune
+	// alphabet is the set of characters allowed in words,
+	// any character is allowed if it is nil
+	alphabet map[rune]bool
 }
 
 // node is the internal representation of a trie node.
@@ -18,6 +37,8 @@ type node struct {
 }
 
 // Init initializes a trie with a given alphabet size.
+// Words may contain any character, the size is only used to
+// preallocate room for the children of each node.
 // A trie must be initialized before it can be used.
 // O(1)
 func (t *T) Init(size rune) {
@@ -27,69 +48,100 @@ func (t *T) Init(size rune) {
 
 	t.root = &node{nodes: make(map[rune]*node, size)}
 	t.size = size
+	t.alphabet = nil
+}
+
+// InitAlphabet initializes a trie whose words may only contain
+// the characters in the given alphabet. With an empty alphabet,
+// only the empty word may be inserted.
+// A trie must be initialized before it can be used.
+// O(k) where k is the size of the alphabet
+func (t *T) InitAlphabet(alphabet string) {
+	a := make(map[rune]bool)
+	for _, c := range alphabet {
+		a[c] = true
+	}
+
+	// the children of most nodes are a small part of a large alphabet,
+	// so only the root is sized for all of it
+	t.Init(1)
+	t.root.nodes = make(map[rune]*node, len(a))
+	t.alphabet = a
 }
 
 // Insert adds a new word to the trie.
 // The word may be accompanied by a value.
+// Words are stored as sequences of runes, so they must be valid UTF-8.
+// If the word isn't valid UTF-8, ErrInvalidUTF8 is returned, and if it has
+// characters outside of the alphabet, ErrAlphabet is returned. In both
+// cases the trie is left unchanged.
 // Average: O(log(n)) Worst: O(n)
-func (t *T) Insert(s string, v interface{}) {
+func (t *T) Insert(s string, v interface{}) error {
+	if !utf8.ValidString(s) {
+		return ErrInvalidUTF8
+	}
+
+	if !t.valid(s) {
+		return ErrAlphabet
+	}
+
 	r := t.root
 
-	for i, c := range s {
-		n := r.next(c, t.size)
-		end := i == len(s)-1
+	for _, c := range s {
+		n := r.next(c)
 		if n == nil {
-			n = &node{c, make(map[rune]*node, t.size), end, r, v}
-			r.nodes[c%t.size] = n
-			// increment the number of children for the parent
-			// this information is useful for deletions
-			if end {
-				t.words++
-			}
-		} else if end && !n.end {
-			// if the node previously existed, but wasn't a terminating string,
-			// we need to now mark it as such (i.e. insert("foobar"), insert("foo"))
-			// also increment the number of words in the trie in this scenario
-			n.end = true
-			n.value = v
-			t.words++
+			n = &node{char: c, nodes: make(map[rune]*node, t.size), parent: r}
+			r.nodes[c] = n
 		}
 
 		// the child becomes the parent
 		r = n
 	}
+
+	// if the node previously existed, but wasn't a terminating string,
+	// we need to now mark it as such (i.e. insert("foobar"), insert("foo"))
+	if !r.end {
+		r.end = true
+		r.value = v
+		t.words++
+	}
+
+	return nil
+}
+
+// valid returns true if all the characters of a word are in the alphabet.
+func (t *T) valid(s string) bool {
+	if t.alphabet == nil {
+		return true
+	}
+
+	for _, c := range s {
+		if !t.alphabet[c] {
+			return false
+		}
+	}
+
+	return true
 }
 
 // Delete returns true if the given word was removed from the trie.
 // Average: O(log(n)) Worst: O(n)
 func (t *T) Delete(s string) bool {
-	n := traverse(t.start(s), s, t.size)
+	n := t.traverse(s)
 
 	// the word doesn't exist in the trie, so nothing to remove
 	if n == nil || !n.end {
 		return false
 	}
 
-	for n != nil {
-		if len(n.nodes) == 0 {
-			// the node has no children, so remove it
-			if n.parent != nil {
-				n.parent.nodes[n.char] = nil
-			}
+	n.end = false
+	n.value = nil
 
-			// move up, but only continue until
-			// we find a terminating node
-			n = n.parent
-			if n.end {
-				break
-			}
-		} else {
-			// the current node has children
-			// in this case, the node is no longer terminating
-			// but nothing can be deleted
-			n.end = false
-			break
-		}
+	// remove the nodes that no longer lead to any word, moving up
+	// until we find a terminating node or one with other children
+	for n.parent != nil && !n.end && len(n.nodes) == 0 {
+		delete(n.parent.nodes, n.char)
+		n = n.parent
 	}
 
 	t.words--
@@ -107,7 +159,7 @@ func (t *T) Has(s string) bool {
 // true if the trie contains the given word.
 // Average: O(log(n)) Worst: O(n)
 func (t *T) Get(s string) (interface{}, bool) {
-	n := traverse(t.start(s), s, t.size)
+	n := t.traverse(s)
 
 	if n == nil || !n.end {
 		return nil, false
@@ -116,43 +168,206 @@ func (t *T) Get(s string) (interface{}, bool) {
 	return n.value, true
 }
 
+// LongestPrefix returns the longest word in the trie that is a prefix
+// of the given string, its value, and true if there is such a word.
+// O(k) where k is the length of the string
+func (t *T) LongestPrefix(s string) (string, interface{}, bool) {
+	var word string
+	var found *node
+
+	for w, n := range t.prefixes(s) {
+		word, found = w, n
+	}
+
+	if found == nil {
+		return "", nil, false
+	}
+
+	return word, found.value, true
+}
+
+// AllPrefixesOf returns an iterator over the words in the trie that are
+// prefixes of the given string and their values, from shortest to longest.
+// O(k) where k is the length of the string
+func (t *T) AllPrefixesOf(s string) iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		for w, n := range t.prefixes(s) {
+			if !yield(w, n.value) {
+				return
+			}
+		}
+	}
+}
+
+// prefixes returns an iterator over the terminating nodes on the path
+// of a given string and their words, walking down the trie once.
+func (t *T) prefixes(s string) iter.Seq2[string, *node] {
+	return func(yield func(string, *node) bool) {
+		n := t.root
+		if n == nil {
+			return
+		}
+
+		if n.end && !yield("", n) {
+			return
+		}
+
+		for i := 0; i < len(s); {
+			// no word has an invalid character, so the path ends there
+			c, size := utf8.DecodeRuneInString(s[i:])
+			if c == utf8.RuneError && size == 1 {
+				return
+			}
+
+			if n = n.next(c); n == nil {
+				return
+			}
+
+			i += size
+			if n.end && !yield(s[:i], n) {
+				return
+			}
+		}
+	}
+}
+
 // StartsWith returns all words in the trie that begin with
-// the given string.
+// the given string, in lexicographic order.
 // O(n)
 func (t *T) StartsWith(s string) (matches []string) {
-	n := traverse(t.start(s), s, t.size)
+	for w := range t.PrefixSeq(s) {
+		matches = append(matches, w)
+	}
+
+	return
+}
+
+// PrefixSeq returns an iterator over the words in the trie that begin
+// with the given string and their values, in lexicographic order.
+// Words are produced lazily, so stopping early skips the rest of the trie.
+// O(n)
+func (t *T) PrefixSeq(s string) iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		if t.root == nil {
+			return
+		}
 
-	return append(matches, match(n, s)...)
+		n := t.traverse(s)
+		if n == nil {
+			return
+		}
+
+		// the buffer is shared by the whole walk, and only
+		// converted to a string for the words that are yielded
+		buf := make([]byte, len(s), len(s)+16)
+		copy(buf, s)
+		match(n, buf, yield)
+	}
 }
 
-// match recurisvely searches for matches for a given string.
-func match(n *node, s string) (matches []string) {
-	if n == nil {
-		return matches
+// match recursively yields the words under a given node in lexicographic order.
+// It returns false once yield asks to stop.
+func match(n *node, buf []byte, yield func(string, interface{}) bool) bool {
+	if n.end && !yield(string(buf), n.value) {
+		return false
 	}
 
-	if n.end {
-		matches = append(matches, s)
+	for _, c := range n.children() {
+		if !match(c, utf8.AppendRune(buf, c.char), yield) {
+			return false
+		}
 	}
 
+	return true
+}
+
+// children returns the children of a node sorted by their character.
+func (n *node) children() []*node {
+	cs := make([]*node, 0, len(n.nodes))
 	for _, c := range n.nodes {
-		if c != nil {
-			matches = append(matches, match(c, s+string(c.char))...)
-		}
+		cs = append(cs, c)
 	}
 
+	slices.SortFunc(cs, func(a, b *node) int {
+		return cmp.Compare(a.char, b.char)
+	})
+
+	return cs
+}
+
+// Match is a word found by a fuzzy search, along with its value
+// and its edit distance from the query.
+type Match struct {
+	Word     string
+	Distance int
+	Value    interface{}
+}
+
+// Fuzzy returns the words in the trie that are within maxEdits
+// insertions, deletions or substitutions of the query, in
+// lexicographic order. The Levenshtein distance is computed one
+// row per node, and branches whose rows already exceed maxEdits
+// are skipped.
+// O(m*k) where m is the number of nodes visited and k is the length of the query
+func (t *T) Fuzzy(query string, maxEdits int) (matches []Match) {
+	if t.root == nil || maxEdits < 0 {
+		return
+	}
+
+	q := []rune(query)
+
+	// the distance between the empty word and each prefix of the query
+	row := make([]int, len(q)+1)
+	for i := range row {
+		row[i] = i
+	}
+
+	fuzzy(t.root, q, row, maxEdits, nil, &matches)
 	return
 }
 
-// traverse returns the last matching node for a given word.
-func traverse(n *node, s string, size rune) *node {
-	for i, c := range s {
-		if n == nil || n.char != c {
-			break
+// fuzzy recursively collects the matches under a given node, where row holds
+// the distances between the node's word and each prefix of the query.
+func fuzzy(n *node, q []rune, row []int, maxEdits int, buf []byte, matches *[]Match) {
+	if d := row[len(q)]; n.end && d <= maxEdits {
+		*matches = append(*matches, Match{string(buf), d, n.value})
+	}
+
+	for _, c := range n.children() {
+		next := make([]int, len(row))
+		next[0] = row[0] + 1
+		low := next[0]
+
+		for i := 1; i < len(row); i++ {
+			cost := 1
+			if q[i-1] == c.char {
+				cost = 0
+			}
+
+			next[i] = min(next[i-1]+1, row[i]+1, row[i-1]+cost)
+			low = min(low, next[i])
 		}
 
-		if i != len(s)-1 {
-			n = n.next(rune(s[i+1]), size)
+		// distances only grow further down, so stop if none are in range
+		if low <= maxEdits {
+			fuzzy(c, q, next, maxEdits, utf8.AppendRune(buf, c.char), matches)
+		}
+	}
+}
+
+// traverse returns the node for a given word, or nil if no word
+// in the trie begins with it. The empty word leads to the root.
+// Words that aren't valid UTF-8 can't have been inserted, so they lead
+// nowhere rather than to the words with utf8.RuneError in their place.
+func (t *T) traverse(s string) *node {
+	if !utf8.ValidString(s) {
+		return nil
+	}
+
+	n := t.root
+	for _, c := range s {
+		if n = n.next(c); n == nil {
+			break
 		}
 	}
 
@@ -162,7 +377,7 @@ func traverse(n *node, s string, size rune) *node {
 // Clear removes all the elements from the trie.
 // O(1)
 func (t *T) Clear() {
-	t.Init(t.size)
+	t.root = &node{nodes: make(map[rune]*node, t.size)}
 	t.words = 0
 }
 
@@ -172,14 +387,8 @@ func (t *T) Len() int {
 	return t.words
 }
 
-// start returns the first node under the root based on the
-// word's first character and the trie's alphabet.
-func (t *T) start(s string) *node {
-	return t.root.nodes[(rune(s[0]) % t.size)]
-}
-
 // next returns the next node under the current node based
-// on the given letter in the word and the trie's alphabet.
-func (n *node) next(r rune, size rune) *node {
-	return n.nodes[r%size]
+// on the given letter in the word.
+func (n *node) next(r rune) *node {
+	return n.nodes[r]
 }
@@ -2,6 +2,9 @@ package trie
 
 import (
 	"fmt"
+	"math/rand"
+	"runtime"
+	"slices"
 	"testing"
 )
 
@@ -69,6 +72,51 @@ func TestStartsWith(t *testing.T) {
 	}
 }
 
+func TestPrefixSeq(t *testing.T) {
+	trie := new(T)
+	trie.Init(256)
+
+	for _, s := range []string{"foobar", "fob", "foo", "f", "bar", "fo"} {
+		trie.Insert(s, len(s))
+	}
+
+	expected := []string{"fo", "fob", "foo", "foobar"}
+	i := 0
+	for w, v := range trie.PrefixSeq("fo") {
+		if i >= len(expected) {
+			t.Errorf("Unexpected match %v: %q", i, w)
+		} else if w != expected[i] {
+			t.Errorf("Expected match %v to be %q, but was %q", i, expected[i], w)
+		} else if v != len(w) {
+			t.Errorf("Value for %q expected to be %v, but was %v", w, len(w), v)
+		}
+		i++
+	}
+
+	if i != len(expected) {
+		t.Errorf("Expected %v matches, but found %v", len(expected), i)
+	}
+
+	if m := trie.StartsWith(""); fmt.Sprint(m) != "[bar f fo fob foo foobar]" {
+		t.Errorf("Unexpected matches for the empty prefix: %v", m)
+	}
+
+	if m := trie.StartsWith("fx"); len(m) != 0 {
+		t.Errorf("Did not expect any matches: %v", m)
+	}
+
+	n := 0
+	for range trie.PrefixSeq("f") {
+		if n++; n == 2 {
+			break
+		}
+	}
+
+	if n != 2 {
+		t.Errorf("Expected to stop after %v matches, but found %v", 2, n)
+	}
+}
+
 func TestLenClear(t *testing.T) {
 	trie := new(T)
 	trie.Init(26)
@@ -131,6 +179,413 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestInsert_NoCollisions(t *testing.T) {
+	trie := new(T)
+	trie.Init(26)
+
+	// 'a' and '{' are 26 characters apart
+	trie.Insert("a", 1)
+	trie.Insert("{", 2)
+	trie.Insert("ab", 3)
+	trie.Insert("{b", 4)
+
+	for s, expected := range map[string]int{"a": 1, "{": 2, "ab": 3, "{b": 4} {
+		if v, ok := trie.Get(s); !ok || v != expected {
+			t.Errorf("Trie value for %q expected to be %v, but was %v (%t)", s, expected, v, ok)
+		}
+	}
+
+	if !trie.Delete("{") || !trie.Has("a") || !trie.Has("{b") {
+		t.Error("Deleting a word should not affect words with other characters")
+	}
+}
+
+func TestInitAlphabet(t *testing.T) {
+	trie := new(T)
+	trie.InitAlphabet("abc")
+
+	if err := trie.Insert("abcab", 1); err != nil {
+		t.Errorf("Word in the alphabet should have been added: %v", err)
+	}
+
+	for _, s := range []string{"abd", "d", "abcabc!", "ABC"} {
+		if err := trie.Insert(s, nil); err != ErrAlphabet {
+			t.Errorf("Inserting %q expected to fail with %v, but got %v", s, ErrAlphabet, err)
+		}
+
+		if trie.Has(s) {
+			t.Errorf("Trie should not contain '%v'", s)
+		}
+	}
+
+	if l := trie.Len(); l != 1 {
+		t.Errorf("Trie length expected to be 1, but instead was %v", l)
+	}
+
+	if m := trie.StartsWith("ab"); len(m) != 1 {
+		t.Errorf("Rejected words should not leave any nodes behind: %v", m)
+	}
+
+	trie.Clear()
+	if err := trie.Insert("d", nil); err != ErrAlphabet {
+		t.Errorf("The alphabet should be kept after Clear, but got %v", err)
+	}
+}
+
+func TestInitAlphabet_Large(t *testing.T) {
+	// a large alphabet, such as a range of CJK characters
+	alphabet := make([]rune, 5000)
+	for i := range alphabet {
+		alphabet[i] = 0x4e00 + rune(i)
+	}
+
+	words := make([]string, 200)
+	for i := range words {
+		w := make([]rune, 4)
+		for j := range w {
+			w[j] = alphabet[rand.Intn(len(alphabet))]
+		}
+		words[i] = string(w)
+	}
+
+	// bytes allocated by inserting the words into an initialized trie
+	inserted := func(init func(*T)) uint64 {
+		trie := new(T)
+		init(trie)
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		for _, w := range words {
+			if err := trie.Insert(w, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		runtime.ReadMemStats(&after)
+
+		return after.TotalAlloc - before.TotalAlloc
+	}
+
+	small := inserted(func(trie *T) { trie.Init(1) })
+	large := inserted(func(trie *T) { trie.InitAlphabet(string(alphabet)) })
+
+	if large > 2*small {
+		t.Errorf("Inserting with a large alphabet expected to allocate about %v bytes, but allocated %v", small, large)
+	}
+}
+
+func TestInitAlphabet_Empty(t *testing.T) {
+	trie := new(T)
+	trie.InitAlphabet("")
+
+	if err := trie.Insert("a", nil); err != ErrAlphabet {
+		t.Errorf("Inserting %q expected to fail with %v, but got %v", "a", ErrAlphabet, err)
+	}
+
+	if err := trie.Insert("", 1); err != nil {
+		t.Errorf("The empty word should have been added: %v", err)
+	}
+
+	if l := trie.Len(); l != 1 {
+		t.Errorf("Trie length expected to be 1, but instead was %v", l)
+	}
+}
+
+func TestDelete_RemovesNodes(t *testing.T) {
+	trie := new(T)
+	trie.Init(256)
+
+	trie.Insert("foo", nil)
+	trie.Insert("foobar", nil)
+	trie.Insert("fox", nil)
+
+	trie.Delete("foobar")
+	if n := trie.traverse("foo"); len(n.nodes) != 0 {
+		t.Errorf("Nodes for a deleted word should be removed, found %v", n.nodes)
+	}
+
+	trie.Delete("foo")
+	trie.Delete("fox")
+	if len(trie.root.nodes) != 0 {
+		t.Errorf("Trie should not have any nodes left, found %v", trie.root.nodes)
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	values := []string{"cafe", "café", "cafés", "caffè", "naïve", "日本", "日本語", "日曜日", "東京", "🙂", "a🙂b"}
+	trie := new(T)
+	trie.Init(256)
+
+	for _, s := range values {
+		if err := trie.Insert(s, s); err != nil {
+			t.Fatalf("Inserting %q failed: %v", s, err)
+		}
+	}
+
+	for _, s := range values {
+		if v, ok := trie.Get(s); !ok || v != s {
+			t.Errorf("Trie expected to contain %q, but found %v (%t)", s, v, ok)
+		}
+	}
+
+	for _, s := range []string{"caf", "日", "日本語!", "\U0001F642\U0001F642", "a", "café\x00"} {
+		if trie.Has(s) {
+			t.Errorf("Unexpected value %q found in trie", s)
+		}
+	}
+
+	expected := slices.Clone(values)
+	slices.Sort(expected)
+
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"", expected},
+		{"caf", []string{"cafe", "caffè", "café", "cafés"}},
+		{"café", []string{"café", "cafés"}},
+		{"日", []string{"日曜日", "日本", "日本語"}},
+		{"日本", []string{"日本", "日本語"}},
+		{"東", []string{"東京"}},
+		{"🙂", []string{"🙂"}},
+		{"a🙂", []string{"a🙂b"}},
+		{"\xe6", nil},
+		{"京", nil},
+	}
+
+	for _, tt := range tests {
+		if m := trie.StartsWith(tt.prefix); !slices.Equal(m, tt.expected) {
+			t.Errorf("StartsWith(%q) expected %q, but was %q", tt.prefix, tt.expected, m)
+		}
+	}
+
+	for _, s := range values {
+		if !trie.Delete(s) {
+			t.Errorf("%q should have been removed", s)
+		}
+	}
+
+	if l := trie.Len(); l != 0 {
+		t.Errorf("Trie expected to be empty, instead has %v words", l)
+	}
+}
+
+func TestInsert_InvalidUTF8(t *testing.T) {
+	trie := new(T)
+	trie.Init(256)
+
+	for _, s := range []string{"\xff", "caf\xc3", "a\xe6\x97"} {
+		if err := trie.Insert(s, nil); err != ErrInvalidUTF8 {
+			t.Errorf("Inserting %q expected to fail with %v, but got %v", s, ErrInvalidUTF8, err)
+		}
+	}
+
+	if l := trie.Len(); l != 0 {
+		t.Errorf("Trie expected to be empty, instead has %v words", l)
+	}
+}
+
+func TestInvalidUTF8_NotFound(t *testing.T) {
+	trie := new(T)
+	trie.Init(256)
+	trie.Insert("\uFFFD", 1)
+
+	if trie.Has("\xff") {
+		t.Error("Trie should not contain an invalid word")
+	}
+
+	if v, ok := trie.Get("\xff"); ok {
+		t.Errorf("Getting an invalid word expected to fail, but found %v", v)
+	}
+
+	if m := trie.StartsWith("\xff"); len(m) != 0 {
+		t.Errorf("No words expected to start with an invalid prefix, but found %q", m)
+	}
+
+	trie.Delete("\xff")
+	if !trie.Has("\uFFFD") {
+		t.Error("Deleting an invalid word should not have removed '\uFFFD'")
+	}
+}
+
+func TestEmptyWord(t *testing.T) {
+	trie := new(T)
+	trie.Init(256)
+
+	if trie.Has("") {
+		t.Error("Empty trie should not contain the empty word")
+	}
+
+	trie.Insert("", 1)
+	trie.Insert("a", 2)
+
+	if v, ok := trie.Get(""); !ok || v != 1 {
+		t.Errorf("Trie expected to contain the empty word, but found %v (%t)", v, ok)
+	}
+
+	if m := trie.StartsWith(""); !slices.Equal(m, []string{"", "a"}) {
+		t.Errorf("StartsWith(%q) expected %q, but was %q", "", []string{"", "a"}, m)
+	}
+
+	if !trie.Delete("") || trie.Has("") || !trie.Has("a") || trie.Len() != 1 {
+		t.Error("Only the empty word should have been removed")
+	}
+}
+
+func TestFuzzy(t *testing.T) {
+	trie := new(T)
+	trie.Init(256)
+
+	for _, s := range []string{"hello", "help", "hell", "yellow", "hallo", "world", "word", "café", "cafe"} {
+		trie.Insert(s, len(s))
+	}
+
+	tests := []struct {
+		query    string
+		edits    int
+		expected []Match
+	}{
+		{"hello", 0, []Match{{"hello", 0, 5}}},
+		{"helo", 1, []Match{{"hell", 1, 4}, {"hello", 1, 5}, {"help", 1, 4}}},
+		{"hxllo", 1, []Match{{"hallo", 1, 5}, {"hello", 1, 5}}},
+		{"wrd", 1, []Match{{"word", 1, 4}}},
+		{"cafe", 0, []Match{{"cafe", 0, 4}}},
+		{"cafe", 1, []Match{{"cafe", 0, 4}, {"café", 1, 5}}},
+		{"xyz", 2, nil},
+		{"hello", -1, nil},
+	}
+
+	for _, tt := range tests {
+		if m := trie.Fuzzy(tt.query, tt.edits); !slices.Equal(m, tt.expected) {
+			t.Errorf("Fuzzy(%q, %v) expected %v, but was %v", tt.query, tt.edits, tt.expected, m)
+		}
+	}
+
+	if m := new(T).Fuzzy("foo", 1); len(m) != 0 {
+		t.Errorf("Uninitialized trie should not have any matches, found %v", m)
+	}
+}
+
+func TestFuzzy_BruteForce(t *testing.T) {
+	trie := new(T)
+	trie.Init(256)
+
+	var words []string
+	for i := 0; i < 500; i++ {
+		b := make([]rune, rand.Intn(7))
+		for j := range b {
+			b[j] = []rune("abcé")[rand.Intn(4)]
+		}
+		words = append(words, string(b))
+		trie.Insert(string(b), nil)
+	}
+
+	for i := 0; i < 50; i++ {
+		query, edits := words[rand.Intn(len(words))]+"a", rand.Intn(3)
+
+		var expected []Match
+		for _, w := range trie.StartsWith("") {
+			if d := levenshtein(query, w); d <= edits {
+				expected = append(expected, Match{w, d, nil})
+			}
+		}
+
+		if m := trie.Fuzzy(query, edits); !slices.Equal(m, expected) {
+			t.Errorf("Fuzzy(%q, %v) expected %v, but was %v", query, edits, expected, m)
+		}
+	}
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	d := make([][]int, len(x)+1)
+	for i := range d {
+		d[i] = make([]int, len(y)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+		}
+	}
+
+	return d[len(x)][len(y)]
+}
+
+func TestLongestPrefix(t *testing.T) {
+	trie := new(T)
+	trie.Init(256)
+
+	for _, s := range []string{"/", "/api", "/api/v1", "/api/v1/users", "/static", "/café", "/\uFFFD"} {
+		trie.Insert(s, s)
+	}
+
+	tests := []struct {
+		s, expected string
+		found       bool
+		all         []string
+	}{
+		{"/api/v1/users/42", "/api/v1/users", true, []string{"/", "/api", "/api/v1", "/api/v1/users"}},
+		{"/api/v1/user", "/api/v1", true, []string{"/", "/api", "/api/v1"}},
+		{"/api/v2", "/api", true, []string{"/", "/api"}},
+		{"/index.html", "/", true, []string{"/"}},
+		{"/cafés", "/café", true, []string{"/", "/café"}},
+		{"/api", "/api", true, []string{"/", "/api"}},
+		{"/\uFFFDx", "/\uFFFD", true, []string{"/", "/\uFFFD"}},
+		{"/\xff", "/", true, []string{"/"}},
+		{"/\xffxyz", "/", true, []string{"/"}},
+		{"api", "", false, nil},
+		{"", "", false, nil},
+	}
+
+	for _, tt := range tests {
+		k, v, ok := trie.LongestPrefix(tt.s)
+		if ok != tt.found || k != tt.expected || ok && v != tt.expected {
+			t.Errorf("LongestPrefix(%q) expected %q (%t), but was %q=%v (%t)", tt.s, tt.expected, tt.found, k, v, ok)
+		}
+
+		var all []string
+		for k, v := range trie.AllPrefixesOf(tt.s) {
+			if v != k {
+				t.Errorf("Value for %q expected to be %q, but was %v", k, k, v)
+			}
+			all = append(all, k)
+		}
+
+		if !slices.Equal(all, tt.all) {
+			t.Errorf("AllPrefixesOf(%q) expected %q, but was %q", tt.s, tt.all, all)
+		}
+	}
+
+	trie.Insert("", "root")
+	if k, v, ok := trie.LongestPrefix("api"); !ok || k != "" || v != "root" {
+		t.Errorf("LongestPrefix(%q) expected the empty word, but was %q=%v (%t)", "api", k, v, ok)
+	}
+
+	n := 0
+	for range trie.AllPrefixesOf("/api/v1/users") {
+		if n++; n == 2 {
+			break
+		}
+	}
+
+	if n != 2 {
+		t.Errorf("Expected to stop after %v prefixes, but found %v", 2, n)
+	}
+
+	if _, _, ok := new(T).LongestPrefix("/"); ok {
+		t.Error("Uninitialized trie should not have any prefixes")
+	}
+}
+
 func (t *T) String() (s string) {
 	s = fmt.Sprintf("%v\n", t.words)
 	print(t.root, "", &s)
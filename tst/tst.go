@@ -1,6 +1,17 @@
 // Package tst implements a ternary search tree.
 package tst
 
+import (
+	"container/heap"
+	"errors"
+	"iter"
+	"math"
+	"unicode/utf8"
+)
+
+// ErrInvalidUTF8 is returned when inserting a word that isn't valid UTF-8.
+var ErrInvalidUTF8 = errors.New("tst: word is not valid UTF-8")
+
 // T is the internal representation of a ternary search tree.
 type T struct {
 	root  *node
@@ -13,46 +24,82 @@ type node struct {
 	char               rune
 	end                bool
 	value              interface{}
+	weight             float64
+	// top is the highest weight of the words in the subtree
+	// rooted at the node, including its lo and hi siblings
+	top float64
 }
 
 // Inserts adds a new word to the tree.
 // The word may be accompanied by a value.
+// Words are stored as sequences of runes, so they must be valid UTF-8,
+// otherwise ErrInvalidUTF8 is returned and the tree is left unchanged.
+// The empty word can't be stored and is ignored.
+// New words have a weight of 0.
 // Average: O(log(n)) Worst: O(n)
-func (t *T) Insert(s string, v interface{}) {
-	t.root = insert(t.root, nil, s, t, v)
+func (t *T) Insert(s string, v interface{}) error {
+	if !utf8.ValidString(s) {
+		return ErrInvalidUTF8
+	}
+
+	if len(s) > 0 {
+		t.insert(s, v).fix()
+	}
+
+	return nil
 }
 
-// insert recusively adds a word to the tree.
-func insert(n, p *node, s string, t *T, v interface{}) *node {
-	if len(s) == 0 {
-		if n != nil && n.parent != nil && !n.parent.end {
-			n.parent.end = true
-			n.parent.value = v
-			t.words++
-		}
+// InsertWeighted adds a new word to the tree like Insert, with a weight
+// used to rank it in TopK. If the word is already in the tree, its value
+// is left unchanged and its weight is replaced.
+// Average: O(log(n)) Worst: O(n)
+func (t *T) InsertWeighted(s string, v interface{}, w float64) error {
+	if !utf8.ValidString(s) {
+		return ErrInvalidUTF8
+	}
 
-		return n
+	if len(s) > 0 {
+		n := t.insert(s, v)
+		n.weight = w
+		n.fix()
 	}
 
-	c := rune(s[0])
-	if n == nil {
-		n = &node{char: c, parent: p}
-		if len(s) == 1 {
-			n.end = true
-			n.value = v
-			t.words++
+	return nil
+}
+
+// insert adds a non-empty word to the tree and returns its terminating node.
+func (t *T) insert(s string, v interface{}) *node {
+	l := &t.root
+	var p *node
+
+	for {
+		c, size := utf8.DecodeRuneInString(s)
+		if *l == nil {
+			*l = &node{char: c, parent: p, top: math.Inf(-1)}
 		}
-	}
 
-	if c < n.char {
-		n.lo = insert(n.lo, n, s, t, v)
-	} else if c > n.char {
-		n.hi = insert(n.hi, n, s, t, v)
-	} else {
-		n.eq = insert(n.eq, n, s[1:len(s)], t, v)
-	}
+		n := *l
+		if c < n.char {
+			l = &n.lo
+		} else if c > n.char {
+			l = &n.hi
+		} else if len(s) > size {
+			l = &n.eq
+			s = s[size:]
+		} else {
+			// the last character of the word, which may already be
+			// in the tree as part of a longer word (i.e. insert("foobar"), insert("foo"))
+			if !n.end {
+				n.end = true
+				n.value = v
+				t.words++
+			}
+
+			return n
+		}
 
-	return n
+		p = n
+	}
 }
 
 // Delete returns true if the word was removed from the tree.
@@ -64,40 +111,54 @@ func (t *T) Delete(s string) bool {
 		return false
 	}
 
-	for n != nil {
-		if !n.hasChildren() {
-			if n.parent == nil {
-				// the node is the root, so just remove it
-				n = nil
-			} else {
-				// remove the link from the parent
-				if n.parent.eq == n {
-					n.parent.eq = nil
-				} else if n.parent.lo == n {
-					n.parent.lo = nil
-				} else if n.parent.hi == n {
-					n.parent.hi = nil
-				}
-
-				// if the parent isn't a terminating node, move up
-				// otherwise, stop where we are
-				if !n.parent.end {
-					n = n.parent
-				} else {
-					n = nil
-				}
-			}
+	n.end = false
+	n.value = nil
+	n.weight = 0
+
+	// remove the nodes that no longer lead to any word, moving up
+	// until we find a terminating node or one with other children
+	for n != nil && !n.end && !n.hasChildren() {
+		p := n.parent
+		if p == nil {
+			t.root = nil
+		} else if p.eq == n {
+			p.eq = nil
+		} else if p.lo == n {
+			p.lo = nil
 		} else {
-			// the node has children, so just mark it as non-terminating
-			n.end = false
-			break
+			p.hi = nil
 		}
+
+		n = p
 	}
 
+	n.fix()
 	t.words--
 	return true
 }
 
+// fix updates the top weights from the node up to the root.
+func (n *node) fix() {
+	for ; n != nil; n = n.parent {
+		top := math.Inf(-1)
+		if n.end {
+			top = n.weight
+		}
+
+		for _, c := range [...]*node{n.lo, n.eq, n.hi} {
+			if c != nil {
+				top = max(top, c.top)
+			}
+		}
+
+		// the ancestors only depend on the top weight of their children
+		if top == n.top {
+			return
+		}
+		n.top = top
+	}
+}
+
 // Has returns true if the tree contains the given word.
 // Average: O(log(n)) Worst: O(n)
 func (t *T) Has(s string) bool {
@@ -119,55 +180,269 @@ func (t *T) Get(s string) (interface{}, bool) {
 	return n.value, true
 }
 
-// StartsWith returns all the words in the trie that begin with
-// the given string.
+// StartsWith returns all the words in the tree that begin with
+// the given string, in lexicographic order.
 // O(n)
 func (t *T) StartsWith(s string) (matches []string) {
-	f, n := traverse(t.root, s)
-	if f {
-		matches = append(matches, s)
+	for w := range t.PrefixSeq(s) {
+		matches = append(matches, w)
 	}
-	matches = append(matches, match(n.eq, s)...)
 
 	return
 }
 
-// match recurisvely searches for matches for a given string.
-func match(n *node, s string) (matches []string) {
+// PrefixSeq returns an iterator over the words in the tree that begin
+// with the given string and their values, in lexicographic order.
+// Words are produced lazily, so stopping early skips the rest of the tree.
+// O(n)
+func (t *T) PrefixSeq(s string) iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		n := t.root
+		if len(s) > 0 {
+			f, p := traverse(t.root, s)
+			if p == nil {
+				return
+			}
+
+			if f && !yield(s, p.value) {
+				return
+			}
+			n = p.eq
+		}
+
+		// the buffer is shared by the whole walk, and only
+		// converted to a string for the words that are yielded
+		buf := make([]byte, len(s), len(s)+16)
+		copy(buf, s)
+		match(n, buf, yield)
+	}
+}
+
+// match recurisvely yields the words under a given node in lexicographic order.
+// It returns false once yield asks to stop.
+func match(n *node, buf []byte, yield func(string, interface{}) bool) bool {
 	if n == nil {
-		return matches
+		return true
+	}
+
+	if !match(n.lo, buf, yield) {
+		return false
+	}
+
+	nb := utf8.AppendRune(buf, n.char)
+	if n.end && !yield(string(nb), n.value) {
+		return false
+	}
+
+	return match(n.eq, nb, yield) && match(n.hi, buf, yield)
+}
+
+// Completion is a word found by TopK, along with its weight and value.
+type Completion struct {
+	Word   string
+	Weight float64
+	Value  interface{}
+}
+
+// TopK returns up to k words in the tree that begin with the given string,
+// in descending order of weight. Words of equal weight are returned in
+// lexicographic order. Subtrees are explored best first, using the highest
+// weight within each of them, so only the branches leading to the
+// results are visited.
+// O(m*log(m)) where m is the number of nodes visited
+func (t *T) TopK(s string, k int) (matches []Completion) {
+	if k < 1 || t.root == nil {
+		return
+	}
+
+	h := candidates{}
+	if len(s) == 0 {
+		h = append(h, candidate{t.root, "", false, t.root.top})
+	} else {
+		f, n := traverse(t.root, s)
+		if n == nil {
+			return
+		}
+
+		if f {
+			h = append(h, candidate{n, s, true, n.weight})
+		}
+		if n.eq != nil {
+			h = append(h, candidate{n.eq, s, false, n.eq.top})
+		}
+		heap.Init(&h)
 	}
 
-	ns := s + string(n.char)
+	for len(h) > 0 && len(matches) < k {
+		c := heap.Pop(&h).(candidate)
+		if c.word {
+			matches = append(matches, Completion{c.s, c.weight, c.n.value})
+			continue
+		}
+
+		n := c.n
+		w := c.s + string(n.char)
+		if n.end {
+			heap.Push(&h, candidate{n, w, true, n.weight})
+		}
 
-	if n.end {
-		matches = append(matches, ns)
+		for _, x := range [...]candidate{{n.lo, c.s, false, 0}, {n.eq, w, false, 0}, {n.hi, c.s, false, 0}} {
+			if x.n != nil {
+				x.weight = x.n.top
+				heap.Push(&h, x)
+			}
+		}
 	}
 
-	matches = append(matches, match(n.eq, ns)...)
-	matches = append(matches, match(n.lo, s)...)
-	matches = append(matches, match(n.hi, s)...)
+	return
+}
+
+// candidate is either a word or a subtree to be explored by TopK.
+type candidate struct {
+	n *node
+	// s is the word, or the prefix leading to the subtree
+	s      string
+	word   bool
+	weight float64
+}
+
+// candidates is a max heap of candidates, where subtrees come before words
+// of the same weight, so that those words are all known when the first one
+// is popped and can be returned in lexicographic order.
+type candidates []candidate
+
+func (h candidates) Len() int      { return len(h) }
+func (h candidates) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h candidates) Less(i, j int) bool {
+	a, b := h[i], h[j]
+	if a.weight != b.weight {
+		return a.weight > b.weight
+	}
+
+	if a.word != b.word {
+		return !a.word
+	}
+
+	return a.word && a.s < b.s
+}
+
+func (h *candidates) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+
+func (h *candidates) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// NearNeighbors returns the words in the tree that have as many characters
+// as the given string and differ from it in at most d of them, i.e. that are
+// within a Hamming distance of d, in lexicographic order.
+// O(n)
+func (t *T) NearNeighbors(s string, d int) (matches []string) {
+	if len(s) > 0 {
+		near(t.root, []rune(s), d, nil, &matches)
+	}
 
 	return
 }
 
-// traverse returns the last matching node for a given word.
+// near recursively collects the words under a given node that are within
+// a Hamming distance of d from the rest of the query.
+func near(n *node, q []rune, d int, buf []byte, matches *[]string) {
+	if n == nil || d < 0 {
+		return
+	}
+
+	// the siblings can only be visited by spending an edit on this character
+	if d > 0 || q[0] < n.char {
+		near(n.lo, q, d, buf, matches)
+	}
+
+	nd := d
+	if q[0] != n.char {
+		nd--
+	}
+
+	nb := utf8.AppendRune(buf, n.char)
+	if len(q) == 1 {
+		if n.end && nd >= 0 {
+			*matches = append(*matches, string(nb))
+		}
+	} else {
+		near(n.eq, q[1:], nd, nb, matches)
+	}
+
+	if d > 0 || q[0] > n.char {
+		near(n.hi, q, d, buf, matches)
+	}
+}
+
+// PartialMatch returns the words in the tree that match the given pattern,
+// in lexicographic order. A '.' in the pattern matches any single character,
+// and every other character matches itself.
+// O(n)
+func (t *T) PartialMatch(pattern string) (matches []string) {
+	if len(pattern) > 0 {
+		partial(t.root, []rune(pattern), nil, &matches)
+	}
+
+	return
+}
+
+// partial recursively collects the words under a given node
+// that match the rest of the pattern.
+func partial(n *node, p []rune, buf []byte, matches *[]string) {
+	if n == nil {
+		return
+	}
+
+	wild := p[0] == '.'
+	if wild || p[0] < n.char {
+		partial(n.lo, p, buf, matches)
+	}
+
+	if wild || p[0] == n.char {
+		nb := utf8.AppendRune(buf, n.char)
+		if len(p) == 1 {
+			if n.end {
+				*matches = append(*matches, string(nb))
+			}
+		} else {
+			partial(n.eq, p[1:], nb, matches)
+		}
+	}
+
+	if wild || p[0] > n.char {
+		partial(n.hi, p, buf, matches)
+	}
+}
+
+// traverse returns the node for the last character of a given word
+// and true if the word is in the tree, or nil if no word begins with it.
+// Words that aren't valid UTF-8 can't have been inserted, so they lead
+// nowhere rather than to the words with utf8.RuneError in their place.
 func traverse(n *node, s string) (bool, *node) {
-	i := 0
+	if len(s) == 0 || !utf8.ValidString(s) {
+		return false, nil
+	}
+
 	for n != nil {
-		if rune(s[i]) < n.char {
+		c, size := utf8.DecodeRuneInString(s)
+		if c < n.char {
 			n = n.lo
-		} else if rune(s[i]) > n.char {
+		} else if c > n.char {
 			n = n.hi
 		} else {
-			if i++; i == len(s) {
+			if s = s[size:]; len(s) == 0 {
 				return n.end, n
 			}
 			n = n.eq
 		}
 	}
 
-	return false, n
+	return false, nil
 }
 
 // Clear removes all the elements from the tree.
@@ -1,7 +1,12 @@
 package tst
 
 import (
+	"cmp"
 	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -66,6 +71,50 @@ func TestStartsWith(t *testing.T) {
 	}
 }
 
+func TestPrefixSeq(t *testing.T) {
+	tst := new(T)
+
+	for _, s := range []string{"foobar", "fob", "foo", "f", "bar", "fo"} {
+		tst.Insert(s, len(s))
+	}
+
+	expected := []string{"fo", "fob", "foo", "foobar"}
+	i := 0
+	for w, v := range tst.PrefixSeq("fo") {
+		if i >= len(expected) {
+			t.Errorf("Unexpected match %v: %q", i, w)
+		} else if w != expected[i] {
+			t.Errorf("Expected match %v to be %q, but was %q", i, expected[i], w)
+		} else if v != len(w) {
+			t.Errorf("Value for %q expected to be %v, but was %v", w, len(w), v)
+		}
+		i++
+	}
+
+	if i != len(expected) {
+		t.Errorf("Expected %v matches, but found %v", len(expected), i)
+	}
+
+	if m := tst.StartsWith(""); fmt.Sprint(m) != "[bar f fo fob foo foobar]" {
+		t.Errorf("Unexpected matches for the empty prefix: %v", m)
+	}
+
+	if m := tst.StartsWith("fx"); len(m) != 0 {
+		t.Errorf("Did not expect any matches: %v", m)
+	}
+
+	n := 0
+	for range tst.PrefixSeq("f") {
+		if n++; n == 2 {
+			break
+		}
+	}
+
+	if n != 2 {
+		t.Errorf("Expected to stop after %v matches, but found %v", 2, n)
+	}
+}
+
 func TestLenClear(t *testing.T) {
 	tst := new(T)
 
@@ -126,6 +175,346 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestUnicode(t *testing.T) {
+	values := []string{"cafe", "café", "cafés", "caffè", "naïve", "日本", "日本語", "日曜日", "東京", "🙂", "a🙂b"}
+	tst := new(T)
+
+	for _, s := range values {
+		if err := tst.Insert(s, s); err != nil {
+			t.Fatalf("Inserting %q failed: %v", s, err)
+		}
+	}
+
+	for _, s := range values {
+		if v, ok := tst.Get(s); !ok || v != s {
+			t.Errorf("Tree expected to contain %q, but found %v (%t)", s, v, ok)
+		}
+	}
+
+	for _, s := range []string{"caf", "日", "日本語!", "\U0001F642\U0001F642", "a", "café\x00"} {
+		if tst.Has(s) {
+			t.Errorf("Unexpected value %q found in tst", s)
+		}
+	}
+
+	expected := slices.Clone(values)
+	slices.Sort(expected)
+
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"", expected},
+		{"caf", []string{"cafe", "caffè", "café", "cafés"}},
+		{"café", []string{"café", "cafés"}},
+		{"日", []string{"日曜日", "日本", "日本語"}},
+		{"日本", []string{"日本", "日本語"}},
+		{"東", []string{"東京"}},
+		{"🙂", []string{"🙂"}},
+		{"a🙂", []string{"a🙂b"}},
+		{"\xe6", nil},
+		{"京", nil},
+	}
+
+	for _, tt := range tests {
+		if m := tst.StartsWith(tt.prefix); !slices.Equal(m, tt.expected) {
+			t.Errorf("StartsWith(%q) expected %q, but was %q", tt.prefix, tt.expected, m)
+		}
+	}
+
+	for _, s := range values {
+		if !tst.Delete(s) {
+			t.Errorf("%q should have been removed", s)
+		}
+	}
+
+	if l := tst.Len(); l != 0 {
+		t.Errorf("Tree expected to be empty, instead has %v words", l)
+	}
+}
+
+func TestInsert_InvalidUTF8(t *testing.T) {
+	tst := new(T)
+
+	for _, s := range []string{"\xff", "caf\xc3", "a\xe6\x97"} {
+		if err := tst.Insert(s, nil); err != ErrInvalidUTF8 {
+			t.Errorf("Inserting %q expected to fail with %v, but got %v", s, ErrInvalidUTF8, err)
+		}
+	}
+
+	if l := tst.Len(); l != 0 {
+		t.Errorf("Tree expected to be empty, instead has %v words", l)
+	}
+}
+
+func TestInvalidUTF8_NotFound(t *testing.T) {
+	tst := new(T)
+	tst.Insert("\uFFFD", 1)
+
+	if tst.Has("\xff") {
+		t.Error("Tree should not contain an invalid word")
+	}
+
+	if v, ok := tst.Get("\xff"); ok {
+		t.Errorf("Getting an invalid word expected to fail, but found %v", v)
+	}
+
+	if m := tst.StartsWith("\xff"); len(m) != 0 {
+		t.Errorf("No words expected to start with an invalid prefix, but found %q", m)
+	}
+
+	tst.Delete("\xff")
+	if !tst.Has("\uFFFD") {
+		t.Error("Deleting an invalid word should not have removed '\uFFFD'")
+	}
+}
+
+func TestNearNeighbors(t *testing.T) {
+	tst := new(T)
+
+	for _, s := range []string{"cat", "cot", "cut", "cart", "bat", "bag", "dog", "ca", "日本", "日曜"} {
+		tst.Insert(s, nil)
+	}
+
+	tests := []struct {
+		s        string
+		d        int
+		expected []string
+	}{
+		{"cat", 0, []string{"cat"}},
+		{"cat", 1, []string{"bat", "cat", "cot", "cut"}},
+		{"bot", 1, []string{"bat", "cot"}},
+		{"bot", 2, []string{"bag", "bat", "cat", "cot", "cut", "dog"}},
+		{"cax", 0, nil},
+		{"ca", 0, []string{"ca"}},
+		{"日x", 1, []string{"日曜", "日本"}},
+		{"cat", -1, nil},
+		{"", 2, nil},
+	}
+
+	for _, tt := range tests {
+		if m := tst.NearNeighbors(tt.s, tt.d); !slices.Equal(m, tt.expected) {
+			t.Errorf("NearNeighbors(%q, %v) expected %q, but was %q", tt.s, tt.d, tt.expected, m)
+		}
+	}
+}
+
+func TestPartialMatch(t *testing.T) {
+	tst := new(T)
+
+	for _, s := range []string{"cat", "cot", "cut", "cart", "bat", "bag", "dog", "ca", "日本", "日曜"} {
+		tst.Insert(s, nil)
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"c.t", []string{"cat", "cot", "cut"}},
+		{"..t", []string{"bat", "cat", "cot", "cut"}},
+		{"ba.", []string{"bag", "bat"}},
+		{"...", []string{"bag", "bat", "cat", "cot", "cut", "dog"}},
+		{"....", []string{"cart"}},
+		{"c.", []string{"ca"}},
+		{"日.", []string{"日曜", "日本"}},
+		{"cat", []string{"cat"}},
+		{"x..", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if m := tst.PartialMatch(tt.pattern); !slices.Equal(m, tt.expected) {
+			t.Errorf("PartialMatch(%q) expected %q, but was %q", tt.pattern, tt.expected, m)
+		}
+	}
+}
+
+func TestNearNeighbors_BruteForce(t *testing.T) {
+	tst := new(T)
+
+	for i := 0; i < 500; i++ {
+		b := make([]byte, 1+rand.Intn(5))
+		for j := range b {
+			b[j] = "abc"[rand.Intn(3)]
+		}
+		tst.Insert(string(b), nil)
+	}
+
+	words := tst.StartsWith("")
+	for i := 0; i < 50; i++ {
+		s, d := words[rand.Intn(len(words))], rand.Intn(3)
+
+		var expected []string
+		for _, w := range words {
+			if hamming(s, w) <= d {
+				expected = append(expected, w)
+			}
+		}
+
+		if m := tst.NearNeighbors(s, d); !slices.Equal(m, expected) {
+			t.Errorf("NearNeighbors(%q, %v) expected %q, but was %q", s, d, expected, m)
+		}
+	}
+}
+
+// hamming returns the number of positions at which two strings of the same
+// length differ, or more than the length of either string if they do not.
+func hamming(a, b string) int {
+	if len(a) != len(b) {
+		return max(len(a), len(b)) + 1
+	}
+
+	d := 0
+	for i := range a {
+		if a[i] != b[i] {
+			d++
+		}
+	}
+
+	return d
+}
+
+func TestTopK(t *testing.T) {
+	tst := new(T)
+
+	weights := map[string]float64{
+		"car": 5, "card": 9, "care": 3, "careful": 7, "cart": 1, "cat": 8, "dog": 10, "日本": 2, "日本語": 4,
+	}
+	for s, w := range weights {
+		tst.InsertWeighted(s, s, w)
+	}
+	tst.Insert("carbon", "carbon")
+
+	tests := []struct {
+		prefix   string
+		k        int
+		expected []string
+	}{
+		{"", 3, []string{"dog", "card", "cat"}},
+		{"car", 3, []string{"card", "careful", "car"}},
+		{"car", 10, []string{"card", "careful", "car", "care", "cart", "carbon"}},
+		{"care", 2, []string{"careful", "care"}},
+		{"ca", 1, []string{"card"}},
+		{"日", 2, []string{"日本語", "日本"}},
+		{"x", 3, nil},
+		{"car", 0, nil},
+	}
+
+	for _, tt := range tests {
+		m := tst.TopK(tt.prefix, tt.k)
+		if w := completed(m); !slices.Equal(w, tt.expected) {
+			t.Errorf("TopK(%q, %v) expected %q, but was %q", tt.prefix, tt.k, tt.expected, w)
+		}
+
+		for _, c := range m {
+			if c.Weight != weights[c.Word] || c.Value != c.Word {
+				t.Errorf("TopK(%q, %v) expected %q with weight %v, but was %v", tt.prefix, tt.k, c.Word, weights[c.Word], c)
+			}
+		}
+	}
+
+	// reweighting and removing words must update the subtree weights
+	tst.InsertWeighted("cart", nil, 20)
+	tst.Delete("dog")
+	expected := []Completion{{"cart", 20, "cart"}, {"card", 9, "card"}}
+	if m := tst.TopK("", 2); !slices.Equal(m, expected) {
+		t.Errorf("TopK(%q, %v) expected %v, but was %v", "", 2, expected, m)
+	}
+	testTop(t, tst)
+}
+
+func TestTopK_BruteForce(t *testing.T) {
+	tst := new(T)
+	weights := make(map[string]float64)
+
+	for i := 0; i < 3000; i++ {
+		b := make([]byte, 1+rand.Intn(5))
+		for j := range b {
+			b[j] = "abc"[rand.Intn(3)]
+		}
+		s := string(b)
+
+		if rand.Intn(4) == 0 {
+			tst.Delete(s)
+			delete(weights, s)
+		} else {
+			// few distinct weights, so there are plenty of ties
+			w := float64(rand.Intn(10))
+			tst.InsertWeighted(s, nil, w)
+			weights[s] = w
+		}
+	}
+	testTop(t, tst)
+
+	for _, prefix := range []string{"", "a", "b", "ab", "cc", "abc"} {
+		var expected []string
+		for s := range weights {
+			if strings.HasPrefix(s, prefix) {
+				expected = append(expected, s)
+			}
+		}
+
+		slices.SortFunc(expected, func(a, b string) int {
+			if c := cmp.Compare(weights[b], weights[a]); c != 0 {
+				return c
+			}
+			return strings.Compare(a, b)
+		})
+
+		k := 10
+		if len(expected) > k {
+			expected = expected[:k]
+		}
+
+		if m := completed(tst.TopK(prefix, k)); !slices.Equal(m, expected) {
+			t.Errorf("TopK(%q, %v) expected %q, but was %q", prefix, k, expected, m)
+		}
+	}
+}
+
+func TestDelete_OnlyWord(t *testing.T) {
+	tst := new(T)
+
+	tst.Insert("a", nil)
+	if !tst.Delete("a") || tst.Has("a") || tst.root != nil {
+		t.Error("The only word in the tree should have been removed")
+	}
+}
+
+// completed returns the words of the completions found by TopK.
+func completed(m []Completion) (words []string) {
+	for _, c := range m {
+		words = append(words, c.Word)
+	}
+
+	return
+}
+
+// testTop fails the test if the top weight of any node is wrong.
+func testTop(t *testing.T, tree *T) {
+	t.Helper()
+
+	var check func(n *node) float64
+	check = func(n *node) float64 {
+		if n == nil {
+			return math.Inf(-1)
+		}
+
+		top := max(check(n.lo), check(n.eq), check(n.hi))
+		if n.end {
+			top = max(top, n.weight)
+		}
+
+		if top != n.top {
+			t.Fatalf("Node %q expected to have a top weight of %v, but has %v", n.char, top, n.top)
+		}
+
+		return top
+	}
+
+	check(tree.root)
+}
+
 func (t *T) String() (s string) {
 	s = fmt.Sprintf("%v\n", t.words)
 	print(t.root, "", "root: ", &s)